@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+)
+
+// appCheckKeysURL publishes the JSON Web Key Set of the keys which sign Firebase App Check tokens.
+const appCheckKeysURL = "https://firebaseappcheck.googleapis.com/v1/jwks"
+
+// AppCheckClaims are the Firebase App Check specific claims of a token, decoded with JWT.UnmarshalClaims.
+type AppCheckClaims struct {
+	AppID string   `json:"sub"` // the Firebase app ID of the attested app
+	AUD   Audience `json:"aud"` // projects/<project-number> and projects/<project-id>
+}
+
+// NewAppCheckKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign Firebase App Check tokens.
+func NewAppCheckKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(appCheckKeysURL, opts...)
+}
+
+// NewAppCheckVerifier returns a Verifier which parses and verifies the Firebase App Check tokens of a project, signed with RS256.
+// Tokens are verified with the keys of NewAppCheckKeyFetcher, their issuer must be https://firebaseappcheck.googleapis.com/<projectNumber>
+// and their audience contain projects/<projectNumber>.
+func NewAppCheckVerifier(projectNumber string, opts ...Option) (*Verifier, error) {
+	return newAppCheckVerifier(context.Background(), NewAppCheckKeyFetcher(), projectNumber, opts)
+}
+
+func newAppCheckVerifier(ctx context.Context, keyFetcher KeyFetcher, projectNumber string, opts []Option) (*Verifier, error) {
+	if projectNumber == "" {
+		return nil, fmt.Errorf("empty Firebase project number")
+	}
+	opts = append([]Option{withChecks(checkAppCheckClaims)}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "https://firebaseappcheck.googleapis.com/"+projectNumber, "projects/"+projectNumber, opts)
+}
+
+// checkAppCheckClaims checks the header and subject of App Check tokens.
+func checkAppCheckClaims(token *JWT) error {
+	if token.Header.ALG != "RS256" || token.Header.TYP != "JWT" {
+		return fmt.Errorf("expected alg RS256 and typ JWT, but token alg is %v and typ %v", token.Header.ALG, token.Header.TYP)
+	}
+	if token.Claims.SUB == "" {
+		return fmt.Errorf("empty app ID")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+func TestAppCheckVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	pub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"RSA","kid":"test","e":"AQAB","n":"%v"}, {"kty":"OKP","crv":"Ed25519","kid":"ed","x":"%v"}]}`, n, base64.RawURLEncoding.EncodeToString(pub))
+	ver, err := newAppCheckVerifier(context.Background(), keyGetterFunc(jwks), "123456", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	claims := func(aud interface{}, sub string) map[string]interface{} {
+		return map[string]interface{}{"iss": "https://firebaseappcheck.googleapis.com/123456", "aud": aud, "sub": sub}
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"app check", testToken(t, key, nil, claims([]string{"projects/123456", "projects/my-project"}, "1:123456:web:abc")), true},
+		{"other project", testToken(t, key, nil, claims([]string{"projects/654321"}, "1:123456:web:abc")), false},
+		{"no app ID", testToken(t, key, nil, claims([]string{"projects/123456"}, "")), false},
+		{"EdDSA", testToken(t, edKey, map[string]interface{}{"kid": "ed"}, claims([]string{"projects/123456"}, "1:123456:web:abc")), false},
+	}
+	for _, test := range tests {
+		token, err := ver.ParseAndVerify(test.token)
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+		if err == nil {
+			var c AppCheckClaims
+			if err := token.UnmarshalClaims(&c); err != nil || c.AppID != "1:123456:web:abc" || !c.AUD.Contains("projects/my-project") {
+				t.Errorf("%v: unexpected claims %+v, %v", test.name, c, err)
+			}
+		}
+	}
+}
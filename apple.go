@@ -0,0 +1,29 @@
+package jwt
+
+import (
+	"context"
+)
+
+// appleKeysURL publishes the JSON Web Key Set of the keys which sign Sign in with Apple ID tokens.
+const appleKeysURL = "https://appleid.apple.com/auth/keys"
+
+// AppleClaims are the Sign in with Apple specific claims of an ID token, decoded with JWT.UnmarshalClaims.
+type AppleClaims struct {
+	Email          string `json:"email"`
+	EmailVerified  Bool   `json:"email_verified"`
+	IsPrivateEmail Bool   `json:"is_private_email"` // email is a private relay address
+	RealUserStatus int    `json:"real_user_status"` // 0 unsupported, 1 unknown, 2 likely real
+	NonceSupported Bool   `json:"nonce_supported"`
+}
+
+// NewAppleKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign Sign in with Apple ID tokens.
+func NewAppleKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(appleKeysURL, opts...)
+}
+
+// NewAppleVerifier returns a Verifier which parses and verifies Sign in with Apple ID tokens issued to clientID,
+// the Services ID of a web app or the bundle ID of a native app.
+// Tokens are verified with the keys of NewAppleKeyFetcher and their issuer must be https://appleid.apple.com.
+func NewAppleVerifier(clientID string, opts ...Option) (*Verifier, error) {
+	return newFetchingVerifier(context.Background(), NewAppleKeyFetcher(), "https://appleid.apple.com", clientID, opts)
+}
@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"testing"
+)
+
+func TestAppleClaims(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), "com.example.web", WithIssuer("https://appleid.apple.com"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	for _, private := range []interface{}{"true", true} {
+		token, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{
+			"iss":              "https://appleid.apple.com",
+			"aud":              "com.example.web",
+			"email":            "abc@privaterelay.appleid.com",
+			"email_verified":   "true",
+			"is_private_email": private,
+			"real_user_status": 2,
+		}))
+		if err != nil {
+			t.Fatalf("token parse fail, %v", err)
+		}
+		if !token.Claims.EmailVerified {
+			t.Errorf("string email_verified not decoded")
+		}
+		var claims AppleClaims
+		if err := token.UnmarshalClaims(&claims); err != nil {
+			t.Fatalf("unmarshal claims failed, %v", err)
+		}
+		if !claims.IsPrivateEmail || !claims.EmailVerified || claims.RealUserStatus != 2 || claims.Email != "abc@privaterelay.appleid.com" {
+			t.Errorf("unexpected claims %+v", claims)
+		}
+	}
+
+	var b Bool
+	if err := b.UnmarshalJSON([]byte(`"yes"`)); err == nil {
+		t.Errorf("invalid boolean not throwing error")
+	}
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// ClientAssertionType is the client_assertion_type parameter of the private_key_jwt client authentication of RFC 7523.
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// clientAssertionLifetime is the time to expiration of client assertions, which are sent right after signing them.
+const clientAssertionLifetime = 5 * time.Minute
+
+// ClientAssertion returns a client assertion authenticating clientID to the OAuth 2.0 token endpoint tokenEndpoint,
+// for the private_key_jwt client authentication of RFC 7523. Its iss and sub are clientID, its aud is tokenEndpoint,
+// and it has a random jti and expires in 5 minutes. It is sent in the client_assertion parameter of token requests,
+// along with the client_assertion_type parameter ClientAssertionType:
+//
+//	assertion, err := signer.ClientAssertion("client-id", "https://auth.example.com/oauth/token")
+//	...
+//	res, err := http.PostForm("https://auth.example.com/oauth/token", url.Values{
+//		"grant_type":            {"client_credentials"},
+//		"client_assertion_type": {jwt.ClientAssertionType},
+//		"client_assertion":      {assertion},
+//	})
+func (s *Signer) ClientAssertion(clientID, tokenEndpoint string) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("generate jti - %w", err)
+	}
+	now := time.Now()
+	return s.Sign(struct {
+		Claims
+		JTI string `json:"jti"`
+	}{
+		Claims: Claims{
+			ISS: clientID,
+			SUB: clientID,
+			AUD: Audience{tokenEndpoint},
+			IAT: now.Unix(),
+			EXP: now.Add(clientAssertionLifetime).Unix(),
+		},
+		JTI: base64.RawURLEncoding.EncodeToString(jti),
+	})
+}
@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+func TestClientAssertion(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	s, err := NewSigner(key, "test")
+	if err != nil {
+		t.Fatalf("New Signer failed, %v", err)
+	}
+	const endpoint = "https://auth.example.com/oauth/token"
+	v, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"test": s.Public()}, endpoint, WithIssuer("client"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	jtis := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		assertion, err := s.ClientAssertion("client", endpoint)
+		if err != nil {
+			t.Fatalf("client assertion failed, %v", err)
+		}
+		verified, err := v.ParseAndVerify(assertion)
+		if err != nil {
+			t.Fatalf("assertion parse fail, %v", err)
+		}
+		if verified.Claims.SUB != "client" {
+			t.Errorf("expected sub client, got %v", verified.Claims.SUB)
+		}
+		if exp := time.Until(time.Unix(verified.Claims.EXP, 0)); exp <= 0 || exp > clientAssertionLifetime {
+			t.Errorf("unexpected expiration in %v", exp)
+		}
+		var claims struct {
+			JTI string `json:"jti"`
+		}
+		if err := verified.UnmarshalClaims(&claims); err != nil || claims.JTI == "" || jtis[claims.JTI] {
+			t.Errorf("unexpected jti %q, %v", claims.JTI, err)
+		}
+		jtis[claims.JTI] = true
+	}
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// AuditEvent is a redacted summary of a failed verification, passed to the hook of WithAuditHook.
+// The claims are unverified, they are empty if the token is malformed.
+type AuditEvent struct {
+	ISS string
+	AUD Audience
+	KID string
+	ALG string
+	// Kind is the kind of check the token failed, 0 if the failure isn't a *ValidationError, e.g. a *KeyFetchError.
+	Kind Kind
+	// Code is the error code of the failure, as returned by ErrorCode.
+	Code string
+	// SourceIP is the IP address of the client, as set by ContextWithSourceIP, e.g. by VerifyRequest.
+	SourceIP string
+	// Err is the failure, without token material unless WithDebugErrors is set.
+	Err error
+}
+
+// WithAuditHook calls hook with every failed verification of ParseAndVerify and VerifyRequest, e.g. to feed security monitoring.
+// ctx is the context of the verification. hook is called synchronously, possibly concurrently.
+func WithAuditHook(hook func(ctx context.Context, e AuditEvent)) Option {
+	return func(v *Verifier) {
+		v.auditHook = hook
+	}
+}
+
+// WithOnVerified calls hook with every token verified by ParseAndVerify and VerifyRequest,
+// e.g. for analytics like counting active users by subject. hook is called synchronously, possibly concurrently.
+func WithOnVerified(hook func(token *JWT)) Option {
+	return func(v *Verifier) {
+		v.onVerified = hook
+	}
+}
+
+// WithOnRejected calls hook with the error of every failed verification of ParseAndVerify and VerifyRequest,
+// before it's mapped by WithErrorFormatter. hook is called synchronously, possibly concurrently.
+func WithOnRejected(hook func(err error)) Option {
+	return func(v *Verifier) {
+		v.onRejected = hook
+	}
+}
+
+// audit calls the audit and rejection hooks, if any, with the failure err of token, which is nil if it's malformed, and logs it.
+func (v *Verifier) audit(ctx context.Context, token *JWT, err error) {
+	if v.onRejected != nil {
+		v.onRejected(err)
+	}
+	if v.auditHook == nil && v.cacheConfig.log == nil {
+		return
+	}
+	e := AuditEvent{Code: ErrorCode(err), Err: err}
+	if token != nil {
+		e.ISS, e.AUD, e.KID, e.ALG = token.Claims.ISS, token.Claims.AUD, token.Header.KID, token.Header.ALG
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		e.Kind = ve.Kind
+	}
+	e.SourceIP, _ = ctx.Value(sourceIPKey{}).(string)
+	v.cacheConfig.log.log(ctx, v.failureLevel, "jwt: verification failed", "code", e.Code, "iss", e.ISS, "kid", e.KID, "source_ip", e.SourceIP, "error", err)
+	if v.auditHook != nil {
+		v.auditHook(ctx, e)
+	}
+}
+
+type sourceIPKey struct{}
+
+// ContextWithSourceIP returns a copy of ctx with the IP address of the client a token is verified for, for the AuditEvent.
+func ContextWithSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, sourceIPKey{}, ip)
+}
+
+// SourceIPContext returns the context of r with the IP address of r.RemoteAddr as source IP, as used by VerifyRequest
+// and the jwthttp middleware, unless it already has a source IP, e.g. taken from the X-Forwarded-For header by a trusted proxy middleware.
+func SourceIPContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if _, ok := ctx.Value(sourceIPKey{}).(string); ok {
+		return ctx
+	}
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ContextWithSourceIP(ctx, ip)
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuditHook(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	var events []AuditEvent
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithAuditHook(func(ctx context.Context, e AuditEvent) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("valid token audited %v", events)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("Authorization", "Bearer "+testToken(t, key, nil, map[string]interface{}{"aud": "other"}))
+	if _, err := ver.VerifyRequest(r); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %v", len(events))
+	}
+	e := events[0]
+	if e.ISS != "https://accounts.google.com" || !e.AUD.Contains("other") || e.KID != "test" || e.ALG != "EdDSA" ||
+		e.Kind != KindInvalidAudience || e.Code != "aud_mismatch" || e.SourceIP != "192.0.2.1" || e.Err == nil {
+		t.Errorf("unexpected audit event %+v", e)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(ContextWithSourceIP(r.Context(), "198.51.100.1"))
+	if _, err := ver.VerifyRequest(r); err == nil {
+		t.Errorf("missing token not throwing error")
+	}
+	if e := events[len(events)-1]; e.SourceIP != "198.51.100.1" || e.Code != "invalid_token" {
+		t.Errorf("unexpected missing token audit event %+v", e)
+	}
+}
+
+func TestVerificationHooks(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	var verified []*JWT
+	var rejected []error
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID,
+		WithOnVerified(func(token *JWT) { verified = append(verified, token) }),
+		WithOnRejected(func(err error) { rejected = append(rejected, err) }),
+		WithErrorFormatter(func(err error) error { return errors.New("unauthorized") }))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"sub": "alice"})); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"aud": "other"})); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	if _, err := ver.VerifyRequest(r); err == nil {
+		t.Errorf("missing token not throwing error")
+	}
+
+	if len(verified) != 1 || verified[0].Claims.SUB != "alice" {
+		t.Errorf("unexpected verified tokens %v", verified)
+	}
+	if len(rejected) != 2 || !errors.Is(rejected[0], ErrInvalidAudience) {
+		t.Errorf("unexpected rejected errors %v", rejected)
+	}
+}
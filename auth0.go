@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// auth0Domain returns domain without scheme and trailing slash, so it may be given as a domain or as an issuer URL.
+func auth0Domain(domain string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(domain, "https://"), "/")
+}
+
+// NewAuth0KeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign the tokens of an Auth0 tenant domain,
+// e.g. example.us.auth0.com or a custom domain.
+func NewAuth0KeyFetcher(domain string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher("https://"+auth0Domain(domain)+"/.well-known/jwks.json", opts...)
+}
+
+// NewAuth0Verifier returns a Verifier which parses and verifies the tokens of an Auth0 tenant domain issued to audience,
+// the API identifier for access tokens or the client ID for ID tokens.
+// Tokens are verified with the keys of NewAuth0KeyFetcher and their issuer must be https://<domain>/,
+// the issuer without its trailing slash is accepted as well.
+func NewAuth0Verifier(domain, audience string, opts ...Option) (*Verifier, error) {
+	return newAuth0Verifier(context.Background(), NewAuth0KeyFetcher(domain), domain, audience, opts)
+}
+
+func newAuth0Verifier(ctx context.Context, keyFetcher KeyFetcher, domain, audience string, opts []Option) (*Verifier, error) {
+	domain = auth0Domain(domain)
+	if domain == "" {
+		return nil, fmt.Errorf("empty Auth0 domain")
+	}
+	issuer := "https://" + domain + "/"
+	opts = append([]Option{func(v *Verifier) {
+		v.checkIssuer = func(token *JWT) error {
+			if token.Claims.ISS != issuer && token.Claims.ISS != strings.TrimSuffix(issuer, "/") {
+				return ErrInvalidIssuer
+			}
+			return nil
+		}
+	}}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, issuer, audience, opts)
+}
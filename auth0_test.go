@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuth0Verifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+
+	tests := []struct {
+		name   string
+		domain string
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"issuer", "example.auth0.com", map[string]interface{}{"iss": "https://example.auth0.com/", "aud": "https://api"}, true},
+		{"issuer without trailing slash", "example.auth0.com", map[string]interface{}{"iss": "https://example.auth0.com", "aud": "https://api"}, true},
+		{"domain as issuer URL", "https://example.auth0.com/", map[string]interface{}{"iss": "https://example.auth0.com/", "aud": "https://api"}, true},
+		{"audience array", "example.auth0.com", map[string]interface{}{"iss": "https://example.auth0.com/", "aud": []string{"https://api", "https://example.auth0.com/userinfo"}}, true},
+		{"other audience", "example.auth0.com", map[string]interface{}{"iss": "https://example.auth0.com/", "aud": []string{"https://other"}}, false},
+		{"other tenant", "example.auth0.com", map[string]interface{}{"iss": "https://other.auth0.com/", "aud": "https://api"}, false},
+		{"issuer prefix", "example.auth0.com", map[string]interface{}{"iss": "https://example.auth0.com//", "aud": "https://api"}, false},
+	}
+	for _, test := range tests {
+		ver, err := newAuth0Verifier(context.Background(), keyGetterFunc(jwks), test.domain, "https://api", nil)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		_, err = ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	if NewAuth0KeyFetcher("https://example.auth0.com/").URL() != "https://example.auth0.com/.well-known/jwks.json" {
+		t.Errorf("unexpected key URL %v", NewAuth0KeyFetcher("https://example.auth0.com/").URL())
+	}
+}
@@ -0,0 +1,152 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// azureAuthority is the Microsoft identity platform authority of the global Azure cloud.
+const azureAuthority = "https://login.microsoftonline.com/"
+
+// azureConsumersTenant is the tenant ID of personal Microsoft accounts.
+const azureConsumersTenant = "9188040d-6c67-4c5b-b112-36a304b66dad"
+
+// NewAzureKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign Microsoft Entra ID (Azure AD) v2.0 tokens of tenant.
+func NewAzureKeyFetcher(tenant string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(azureAuthority+tenant+"/discovery/v2.0/keys", opts...)
+}
+
+// NewAzureVerifier returns a Verifier which parses and verifies Microsoft Entra ID (Azure AD) v2.0 tokens issued to clientID.
+// tenant is a tenant ID, or common, organizations or consumers for multi-tenant applications.
+// Tokens are verified with the keys of NewAzureKeyFetcher and their issuer must be
+// https://login.microsoftonline.com/{tenantid}/v2.0 with {tenantid} their tid claim,
+// which must be tenant if tenant is a tenant ID.
+// Multi-tenant applications may restrict the tenants with WithAzureTenants.
+func NewAzureVerifier(tenant, clientID string, opts ...Option) (*Verifier, error) {
+	return newAzureVerifier(context.Background(), NewAzureKeyFetcher(tenant), tenant, clientID, opts)
+}
+
+func newAzureVerifier(ctx context.Context, keyFetcher KeyFetcher, tenant, clientID string, opts []Option) (*Verifier, error) {
+	var check func(tid string) error
+	switch tenant {
+	case "common":
+		check = func(tid string) error { return nil }
+	case "organizations":
+		check = func(tid string) error {
+			if tid == azureConsumersTenant {
+				return fmt.Errorf("personal Microsoft account not allowed")
+			}
+			return nil
+		}
+	case "consumers":
+		tenant = azureConsumersTenant
+		fallthrough
+	default:
+		if !isGUID(tenant) {
+			return nil, fmt.Errorf("invalid Azure tenant ID %v", tenant)
+		}
+		check = func(tid string) error {
+			if !strings.EqualFold(tid, tenant) {
+				return fmt.Errorf("tenant %v does not match", tid)
+			}
+			return nil
+		}
+	}
+
+	opts = append([]Option{withAzureIssuer(), withChecks(func(token *JWT) error {
+		claims, err := azureClaimsOf(token)
+		if err != nil {
+			return err
+		}
+		return check(claims.TID)
+	})}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "", clientID, opts)
+}
+
+// WithAzureTenants only accepts tokens of the given tenant IDs, e.g. for a multi-tenant application serving some tenants.
+func WithAzureTenants(tenantIDs ...string) Option {
+	return withChecks(func(token *JWT) error {
+		claims, err := azureClaimsOf(token)
+		if err != nil {
+			return err
+		}
+		for _, tid := range tenantIDs {
+			if strings.EqualFold(tid, claims.TID) {
+				return nil
+			}
+		}
+		return fmt.Errorf("tenant %v not allowed", claims.TID)
+	})
+}
+
+// WithAzureApps only accepts tokens requested by the given application IDs, the appid claim of v1.0 access tokens
+// or the azp claim of v2.0 tokens, e.g. for an API called by known client applications.
+func WithAzureApps(appIDs ...string) Option {
+	return withChecks(func(token *JWT) error {
+		claims, err := azureClaimsOf(token)
+		if err != nil {
+			return err
+		}
+		app := claims.AppID
+		if app == "" {
+			app = token.Claims.AZP
+		}
+		for _, id := range appIDs {
+			if strings.EqualFold(id, app) {
+				return nil
+			}
+		}
+		return fmt.Errorf("application %v not allowed", app)
+	})
+}
+
+// azureClaims are the Microsoft specific claims of a token.
+type azureClaims struct {
+	TID   string `json:"tid"`
+	AppID string `json:"appid"`
+}
+
+func azureClaimsOf(token *JWT) (azureClaims, error) {
+	var claims azureClaims
+	if err := token.UnmarshalClaims(&claims); err != nil {
+		return claims, fmt.Errorf("unable to json decode claims, %w", err)
+	}
+	return claims, nil
+}
+
+// withAzureIssuer expects the issuer https://login.microsoftonline.com/{tenantid}/v2.0 with the tid claim of the token.
+func withAzureIssuer() Option {
+	return func(v *Verifier) {
+		v.checkIssuer = func(token *JWT) error {
+			claims, err := azureClaimsOf(token)
+			if err != nil {
+				return err
+			}
+			if claims.TID == "" || token.Claims.ISS != azureAuthority+claims.TID+"/v2.0" {
+				return ErrInvalidIssuer
+			}
+			return nil
+		}
+	}
+}
+
+// isGUID reports whether s has the 8-4-4-4-12 hex digits form of a GUID.
+func isGUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAzureVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	const tenant = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	const otherTenant = "f8cdef31-a31e-4b4a-93e4-5f571e91255a"
+	token := func(tid string, claims map[string]interface{}) string {
+		c := map[string]interface{}{"iss": "https://login.microsoftonline.com/" + tid + "/v2.0", "tid": tid, "aud": "app"}
+		for k, v := range claims {
+			c[k] = v
+		}
+		return testToken(t, key, nil, c)
+	}
+
+	tests := []struct {
+		name   string
+		tenant string
+		opts   []Option
+		token  string
+		valid  bool
+	}{
+		{"tenant", tenant, nil, token(tenant, nil), true},
+		{"other tenant", tenant, nil, token(otherTenant, nil), false},
+		{"issuer of other tenant", tenant, nil, token(tenant, map[string]interface{}{"iss": "https://login.microsoftonline.com/" + otherTenant + "/v2.0"}), false},
+		{"no tid", tenant, nil, token(tenant, map[string]interface{}{"tid": nil, "iss": nil}), false},
+		{"common", "common", nil, token(otherTenant, nil), true},
+		{"common issuer mismatch", "common", nil, token(otherTenant, map[string]interface{}{"tid": tenant}), false},
+		{"organizations consumer", "organizations", nil, token(azureConsumersTenant, nil), false},
+		{"consumers", "consumers", nil, token(azureConsumersTenant, nil), true},
+		{"allowed tenant", "common", []Option{WithAzureTenants(tenant)}, token(tenant, nil), true},
+		{"not allowed tenant", "common", []Option{WithAzureTenants(tenant)}, token(otherTenant, nil), false},
+		{"allowed appid", tenant, []Option{WithAzureApps("client")}, token(tenant, map[string]interface{}{"appid": "client"}), true},
+		{"allowed azp", tenant, []Option{WithAzureApps("client")}, token(tenant, map[string]interface{}{"azp": "client"}), true},
+		{"not allowed app", tenant, []Option{WithAzureApps("client")}, token(tenant, map[string]interface{}{"appid": "other"}), false},
+	}
+	for _, test := range tests {
+		ver, err := newAzureVerifier(context.Background(), keyGetterFunc(jwks), test.tenant, "app", test.opts)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		_, err = ver.ParseAndVerify(test.token)
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	if _, err := newAzureVerifier(context.Background(), keyGetterFunc(jwks), "contoso.onmicrosoft.com", "app", nil); err == nil {
+		t.Errorf("invalid tenant not throwing error")
+	}
+}
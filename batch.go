@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Result is the result of the verification of a token by VerifyAll.
+type Result struct {
+	// Token is the verified token, nil if Err is set.
+	Token *JWT
+	// Err is the failure of the verification, as returned by ParseAndVerifyContext.
+	Err error
+}
+
+// VerifyAll verifies tokens with ParseAndVerifyContext by GOMAXPROCS go routines, e.g. for batch jobs
+// which validate stored tokens, and returns their results in the order of tokens.
+// Once ctx is done, the tokens which weren't verified yet fail with ctx.Err().
+func (v *Verifier) VerifyAll(ctx context.Context, tokens []string) []Result {
+	results := make([]Result, len(tokens))
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(tokens) {
+		workers = len(tokens)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Token, results[i].Err = v.ParseAndVerifyContext(ctx, tokens[i])
+			}
+		}()
+	}
+	for i := range tokens {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVerifyAll(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	tokens := make([]string, 100)
+	for i := range tokens {
+		claims := map[string]interface{}{"sub": string(rune('a' + i%26))}
+		if i%10 == 0 {
+			claims["aud"] = "other"
+		}
+		tokens[i] = testToken(t, key, nil, claims)
+	}
+
+	results := ver.VerifyAll(context.Background(), tokens)
+	if len(results) != len(tokens) {
+		t.Fatalf("expected %v results, got %v", len(tokens), len(results))
+	}
+	for i, r := range results {
+		if i%10 == 0 {
+			if !errors.Is(r.Err, ErrInvalidAudience) || r.Token != nil {
+				t.Errorf("invalid audience of token %v not throwing error, %v", i, r.Err)
+			}
+		} else if r.Err != nil || r.Token.Claims.SUB != string(rune('a'+i%26)) {
+			t.Errorf("unexpected result of token %v, %+v", i, r)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, r := range ver.VerifyAll(ctx, tokens) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("token %v verified after cancel, %v", i, r.Err)
+		}
+	}
+	if results := ver.VerifyAll(ctx, nil); len(results) != 0 {
+		t.Errorf("unexpected results %v", results)
+	}
+}
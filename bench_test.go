@@ -0,0 +1,118 @@
+package jwt
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseAndVerifyAllocBudget is the maximal number of allocations of a verification of validToken with cached keys,
+// as enforced by TestParseAndVerifyAllocs. The latency targets are indicative, as measured by the benchmarks
+// on a single server core: 40µs for RS256 with 2048 bit keys and 70µs for EdDSA, dominated by the signature
+// verification, 50µs with a key fetch and 2µs with WithResultCache.
+const parseAndVerifyAllocBudget = 20
+
+func BenchmarkParseAndVerify(b *testing.B) {
+	ver, err := NewVerifier(keyGetterFunc(validKey), testClientID)
+	if err != nil {
+		b.Fatalf("New Verifier failed, %v", err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ver.ParseAndVerify(validToken); err != nil {
+			b.Fatalf("token parse fail, %v", err)
+		}
+	}
+}
+
+func BenchmarkParseAndVerifyEdDSA(b *testing.B) {
+	key, jwks := testEd25519Key(b)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		b.Fatalf("New Verifier failed, %v", err)
+	}
+	token := testToken(b, key, nil, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ver.ParseAndVerify(token); err != nil {
+			b.Fatalf("token parse fail, %v", err)
+		}
+	}
+}
+
+// BenchmarkParseAndVerifyCacheMiss fetches and parses the keys for every verification.
+func BenchmarkParseAndVerifyCacheMiss(b *testing.B) {
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		return io.NopCloser(strings.NewReader(validKey)), time.Now().Add(-time.Second), nil
+	}
+	ver, err := NewVerifier(fetcher, testClientID)
+	if err != nil {
+		b.Fatalf("New Verifier failed, %v", err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ver.ParseAndVerify(validToken); err != nil {
+			b.Fatalf("token parse fail, %v", err)
+		}
+	}
+}
+
+func BenchmarkParseAndVerifyParallel(b *testing.B) {
+	ver, err := NewVerifier(keyGetterFunc(validKey), testClientID)
+	if err != nil {
+		b.Fatalf("New Verifier failed, %v", err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := ver.ParseAndVerifyContext(context.Background(), validToken); err != nil {
+				b.Errorf("token parse fail, %v", err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkParseAndVerifyResultCache(b *testing.B) {
+	ver, err := NewVerifier(keyGetterFunc(validKey), testClientID, WithResultCache(1000))
+	if err != nil {
+		b.Fatalf("New Verifier failed, %v", err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ver.ParseAndVerify(validToken); err != nil {
+			b.Fatalf("token parse fail, %v", err)
+		}
+	}
+}
+
+func BenchmarkParseJWKS(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseJWKS(strings.NewReader(validKey)); err != nil {
+			b.Fatalf("parse JWKS failed, %v", err)
+		}
+	}
+}
+
+func TestParseAndVerifyAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not representative with the race detector")
+	}
+	ver, err := NewVerifier(keyGetterFunc(validKey), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := ver.ParseAndVerify(validToken); err != nil {
+			t.Fatalf("token parse fail, %v", err)
+		}
+	})
+	t.Logf("%v allocations per verification", allocs)
+	if allocs > parseAndVerifyAllocBudget {
+		t.Errorf("%v allocations per verification exceed the budget of %v", allocs, parseAndVerifyAllocBudget)
+	}
+}
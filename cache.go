@@ -0,0 +1,385 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minBackgroundRefresh is the minimal time between two background refreshes, it prevents a busy loop
+// when the fetcher fails or the keys expire sooner than the refresh ahead duration.
+var minBackgroundRefresh = time.Second * 10
+
+// cacheConfig holds the key cache configuration set by Verifier options.
+type cacheConfig struct {
+	background        bool
+	refreshAhead      time.Duration
+	unknownKeyRefresh time.Duration
+	minTTL            time.Duration
+	maxTTL            time.Duration
+	jitter            time.Duration
+	store             KeyCacheStore
+	storeKey          string
+	jwks              jwksConfig
+	pinned            map[string]bool // the pinned key thumbprints, nil if all keys are used
+	log               logFunc
+	tracer            Tracer
+	metrics           MetricsRecorder
+	rotationHook      func(context.Context, KeyRotation)
+	clock             func() time.Time // the time the keys expire at, time.Now if nil
+}
+
+// now returns the time of the clock of the cache.
+func (c cacheConfig) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// expiration clamps the time until the fetched keys expire to the configured TTL range and subtracts a random jitter.
+// The keys expire after the TTL at the clock of the cache.
+func (c cacheConfig) expiration(expires time.Time) time.Time {
+	ttl := time.Until(expires)
+	if ttl < c.minTTL {
+		ttl = c.minTTL
+	}
+	if c.maxTTL > 0 && ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+	if c.jitter > 0 {
+		ttl -= time.Duration(rand.Int63n(int64(c.jitter)))
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.now().Add(ttl)
+}
+
+// keySet is an immutable snapshot of the cached keys.
+type keySet struct {
+	publicKeys map[string]verificationKey // nil if the keys were never set
+	// thumbprints maps the RFC 7638 thumbprints of publicKeys to the keys
+	thumbprints map[string]verificationKey
+	expires     time.Time
+}
+
+// emptyKeySet is the keySet of a cache whose keys were never set.
+var emptyKeySet = &keySet{}
+
+type keyCache struct {
+	keyFetcher KeyFetcher
+	// keys holds the *keySet, which is replaced rather than modified so that lookups don't lock.
+	// It's only stored with fetchMu held, or before the cache is used.
+	keys atomic.Value
+
+	// mu guards lastFetch, lastFetchErr and fetchErrors, the outcomes of fetch reported by stats
+	mu           sync.Mutex
+	lastFetch    time.Time
+	lastFetchErr error
+	fetchErrors  int
+
+	// fetchMu serializes fetches and guards lastUnknownRefresh and raw
+	fetchMu            sync.Mutex
+	lastUnknownRefresh time.Time
+	raw                []byte // the keys as returned by keyFetcher
+
+	config cacheConfig
+	stop   context.CancelFunc
+	done   chan struct{}
+}
+
+func newKeyCache(ctx context.Context, keyFetcher KeyFetcher, config cacheConfig) (*keyCache, error) {
+	k := &keyCache{
+		keyFetcher: keyFetcher,
+		config:     config,
+	}
+	if !k.load(ctx) {
+		if err := k.refresh(ctx); err != nil {
+			return k, err
+		}
+	}
+	if config.background {
+		bgCtx, cancel := context.WithCancel(context.Background())
+		k.stop = cancel
+		k.done = make(chan struct{})
+		go k.refreshLoop(bgCtx)
+	}
+	return k, nil
+}
+
+// newStaticKeyCache returns a keyCache which never expires nor refreshes keys.
+func newStaticKeyCache(keys map[string]crypto.PublicKey, config cacheConfig) (*keyCache, error) {
+	if err := checkKeys(keys); err != nil {
+		return nil, err
+	}
+	m := make(map[string]verificationKey, len(keys))
+	for kid, k := range keys {
+		m[kid] = verificationKey{key: k}
+	}
+	c := &keyCache{config: config}
+	if _, err := c.setKeys(m, time.Time{}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// refreshLoop refreshes the keys ahead of their expiration until ctx is done.
+// A failed refresh is retried after minBackgroundRefresh, if the keys expire in the meantime retrieveKey fetches them.
+func (v *keyCache) refreshLoop(ctx context.Context) {
+	defer close(v.done)
+	for {
+		wait := v.snapshot().expires.Sub(v.config.now()) - v.config.refreshAhead
+		if wait < minBackgroundRefresh {
+			wait = minBackgroundRefresh
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if err := v.refresh(ctx); err != nil {
+			v.config.log.log(ctx, levelWarn, "jwt: background key refresh failed", "error", err)
+		}
+	}
+}
+
+// shutdown stops the background refresh and waits for it to return or ctx to be done.
+func (v *keyCache) shutdown(ctx context.Context) error {
+	if v.stop == nil {
+		return nil
+	}
+	v.stop()
+	select {
+	case <-v.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// UpdatePublicKey sets the verifier public key to the key obtained from jwksReader and reports the rotation, if any.
+func (v *keyCache) UpdatePublicKey(ctx context.Context, jwksReader io.Reader, expiration time.Time) error {
+	m, err := v.config.jwks.parse(jwksReader)
+	if err != nil {
+		return fmt.Errorf("unable to parse JWKS %w", err)
+	}
+	r, err := v.setKeys(m, expiration)
+	if err != nil {
+		return err
+	}
+	v.reportRotation(ctx, r)
+	return nil
+}
+
+// setKeys replaces the cached keys with the pinned keys of m and indexes them by their thumbprint.
+// It returns the rotation of the replaced keys, which is empty if there were none. fetchMu must be held once the cache is used.
+func (v *keyCache) setKeys(m map[string]verificationKey, expiration time.Time) (KeyRotation, error) {
+	thumbprints := make(map[string]verificationKey, len(m))
+	for kid, k := range m {
+		tp, err := Thumbprint(k.key)
+		if err != nil {
+			return KeyRotation{}, err
+		}
+		if v.config.pinned != nil && !v.config.pinned[tp] {
+			delete(m, kid)
+			continue
+		}
+		thumbprints[tp] = k
+	}
+	if len(m) == 0 {
+		return KeyRotation{}, fmt.Errorf("no pinned public keys")
+	}
+
+	var r KeyRotation
+	if old := v.snapshot(); old.publicKeys != nil {
+		r = diffKeys(old.publicKeys, m)
+	}
+	v.keys.Store(&keySet{publicKeys: m, thumbprints: thumbprints, expires: expiration})
+	return r, nil
+}
+
+// snapshot returns the cached keys.
+func (v *keyCache) snapshot() *keySet {
+	if s, ok := v.keys.Load().(*keySet); ok {
+		return s
+	}
+	return emptyKeySet
+}
+
+// lookup returns the key with kid, or with thumbprint kid if no key has that kid.
+func (v *keyCache) lookup(kid string) verificationKey {
+	s := v.snapshot()
+	if k, ok := s.publicKeys[kid]; ok {
+		return k
+	}
+	return s.thumbprints[kid]
+}
+
+// keyFetcher updates the key cache if it's expired and returns the requested key by kid or thumbprint.
+// If key is not in cache, a nil key is returned.
+// A static cache, without keyFetcher, is never updated.
+// An unknown kid triggers a refresh, at most once per config.unknownKeyRefresh, in case the keys were rotated.
+func (v *keyCache) retrieveKey(ctx context.Context, kid string) (verificationKey, error) {
+	if v.keyFetcher == nil {
+		return v.lookup(kid), nil
+	}
+
+	if v.snapshot().expires.Before(v.config.now()) {
+		if err := v.refreshExpired(ctx); err != nil {
+			return verificationKey{}, err
+		}
+	}
+
+	k := v.lookup(kid)
+	if k.key != nil || kid == "" || v.config.unknownKeyRefresh <= 0 {
+		return k, nil
+	}
+
+	if err := v.refreshUnknown(ctx, kid); err != nil {
+		return verificationKey{}, err
+	}
+	return v.lookup(kid), nil
+}
+
+// retrieveKeys updates the key cache if it's expired and returns all keys, sorted by their kid.
+func (v *keyCache) retrieveKeys(ctx context.Context) ([]verificationKey, error) {
+	if v.keyFetcher != nil {
+		if v.snapshot().expires.Before(v.config.now()) {
+			if err := v.refreshExpired(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s := v.snapshot()
+	kids := make([]string, 0, len(s.publicKeys))
+	for kid := range s.publicKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	keys := make([]verificationKey, len(kids))
+	for i, kid := range kids {
+		keys[i] = s.publicKeys[kid]
+	}
+	return keys, nil
+}
+
+// refresh fetches the keys and updates the cache, unless the store has newer keys.
+func (v *keyCache) refresh(ctx context.Context) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if v.load(ctx) {
+		return nil
+	}
+	return v.fetch(ctx)
+}
+
+// refreshExpired refreshes the keys unless they were already refreshed by another go routine.
+func (v *keyCache) refreshExpired(ctx context.Context) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if !v.snapshot().expires.Before(v.config.now()) || v.load(ctx) {
+		return nil
+	}
+	v.config.log.log(ctx, levelDebug, "jwt: keys expired")
+	return v.fetch(ctx)
+}
+
+// refreshUnknown refreshes the keys if kid is still unknown and the last refresh caused by an unknown kid
+// is older than config.unknownKeyRefresh. The store is skipped since the keys may be rotated since they were stored.
+func (v *keyCache) refreshUnknown(ctx context.Context, kid string) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if time.Since(v.lastUnknownRefresh) < v.config.unknownKeyRefresh {
+		return nil
+	}
+	if v.lookup(kid).key != nil {
+		return nil
+	}
+	v.lastUnknownRefresh = time.Now()
+	v.config.log.log(ctx, levelDebug, "jwt: refreshing keys for unknown kid", "kid", kid)
+	return v.fetch(ctx)
+}
+
+// fetch fetches the keys and updates the cache, fetchMu must be held.
+func (v *keyCache) fetch(ctx context.Context) (err error) {
+	reportFetch(ctx)
+	if v.config.tracer != nil {
+		var span Span
+		ctx, span = v.config.tracer.Start(ctx, "jwt.FetchKeys")
+		defer func() { span.End(err) }()
+	}
+	if v.config.metrics != nil {
+		defer func(start time.Time) { v.config.metrics.KeyFetch(time.Since(start), err) }(time.Now())
+	}
+	defer func() { v.recordFetch(err) }()
+	reader, expires, err := v.keyFetcher.Fetch(ctx)
+	expires = v.config.expiration(expires)
+	if errors.Is(err, ErrNotModified) && reader != nil {
+		defer reader.Close()
+		s := v.snapshot()
+		if s.publicKeys != nil {
+			v.keys.Store(&keySet{publicKeys: s.publicKeys, thumbprints: s.thumbprints, expires: expires})
+			v.config.log.log(ctx, levelDebug, "jwt: keys not modified", "expires", expires)
+			v.persist(ctx, expires)
+			return nil
+		}
+	} else if err != nil {
+		v.config.log.log(ctx, levelWarn, "jwt: key fetch failed", "error", err)
+		return fmt.Errorf("fetch key - %w", err)
+	} else {
+		defer reader.Close()
+	}
+	raw, err := readLimited(reader, v.config.jwks.maxSize)
+	if err != nil {
+		return fmt.Errorf("read key - %w", err)
+	}
+	if err = v.UpdatePublicKey(ctx, bytes.NewReader(raw), expires); err != nil {
+		v.config.log.log(ctx, levelWarn, "jwt: key update failed", "error", err)
+		return fmt.Errorf("update key cache - %w", err)
+	}
+	v.raw = raw
+	v.persist(ctx, expires)
+	v.config.log.log(ctx, levelInfo, "jwt: keys refreshed", "keys", len(v.snapshot().publicKeys), "expires", expires)
+	return nil
+}
+
+// load sets the keys from config.store and reports whether they expire after the cached keys and are still fresh.
+func (v *keyCache) load(ctx context.Context) bool {
+	if v.config.store == nil {
+		return false
+	}
+	raw, expires, err := v.config.store.Get(ctx, v.config.storeKey)
+	if err != nil || expires.Before(v.config.now()) {
+		return false
+	}
+	if !expires.After(v.snapshot().expires) {
+		return false
+	}
+	if err := v.UpdatePublicKey(ctx, bytes.NewReader(raw), expires); err != nil {
+		return false
+	}
+	v.raw = raw
+	return true
+}
+
+// persist sets the raw keys in config.store, fetchMu must be held.
+// Failures are ignored, the keys are then fetched again by the next cache.
+func (v *keyCache) persist(ctx context.Context, expires time.Time) {
+	if v.config.store == nil || v.raw == nil {
+		return
+	}
+	_ = v.config.store.Set(ctx, v.config.storeKey, v.raw, expires)
+}
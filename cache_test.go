@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheConfigExpiration(t *testing.T) {
+	config := cacheConfig{minTTL: time.Minute * 5, maxTTL: time.Hour * 24}
+	tests := []struct {
+		ttl, expected time.Duration
+	}{
+		{ttl: 0, expected: time.Minute * 5},
+		{ttl: time.Hour, expected: time.Hour},
+		{ttl: time.Hour * 48, expected: time.Hour * 24},
+	}
+	for _, v := range tests {
+		ttl := time.Until(config.expiration(time.Now().Add(v.ttl)))
+		if ttl > v.expected || ttl < v.expected-time.Second {
+			t.Errorf("expected ttl %v for %v, got %v", v.expected, v.ttl, ttl)
+		}
+	}
+
+	config.jitter = time.Minute
+	for i := 0; i < 100; i++ {
+		ttl := time.Until(config.expiration(time.Now().Add(time.Hour)))
+		if ttl > time.Hour || ttl < time.Hour-time.Minute-time.Second {
+			t.Fatalf("jittered ttl %v out of range", ttl)
+		}
+	}
+}
+
+func TestWithCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	var fetches int32
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		atomic.AddInt32(&fetches, 1)
+		return io.NopCloser(strings.NewReader(validKey)), time.Now().Add(time.Hour), nil
+	}
+
+	if _, err := NewVerifier(fetcher, testClientID, WithCacheFile(path)); err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	ver, err := NewVerifier(fetcher, testClientID, WithCacheFile(path))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected keys loaded from cache file, got %v fetches", n)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	if err := fileCacheStore(path).Set(context.Background(), "", []byte(validKey), time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("write cache file failed, %v", err)
+	}
+	if _, err := NewVerifier(fetcher, testClientID, WithCacheFile(path)); err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected expired cache file to be fetched, got %v fetches", n)
+	}
+}
+
+// memoryStore is a KeyCacheStore for tests.
+type memoryStore struct {
+	mu      sync.Mutex
+	keys    []byte
+	expires time.Time
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keys == nil {
+		return nil, time.Time{}, ErrCacheMiss
+	}
+	return m.keys, m.expires, nil
+}
+
+func (m *memoryStore) Set(ctx context.Context, key string, keys []byte, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys, m.expires = keys, expires
+	return nil
+}
+
+func TestWithKeyCacheStore(t *testing.T) {
+	store := &memoryStore{}
+	var fetches int32
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		atomic.AddInt32(&fetches, 1)
+		return io.NopCloser(strings.NewReader(validKey)), time.Now().Add(time.Hour), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		ver, err := NewVerifier(fetcher, testClientID, WithKeyCacheStore(store, googleCertsURL))
+		if err != nil {
+			t.Fatalf("New Verifier failed, %v", err)
+		}
+		if _, err := ver.ParseAndVerify(validToken); err != nil {
+			t.Errorf("token parse fail, %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected keys from store, got %v fetches", n)
+	}
+}
+
+func TestCacheClock(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	var fetches int32
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		atomic.AddInt32(&fetches, 1)
+		return io.NopCloser(strings.NewReader(jwks)), time.Now().Add(time.Hour), nil
+	}
+	now := time.Now()
+	ver, err := NewVerifier(fetcher, testClientID, WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	claims := map[string]interface{}{"exp": now.Add(time.Hour * 24).Unix()}
+	for i, header := range []map[string]interface{}{{"kid": nil}, nil, {"kid": nil}} {
+		if _, err := ver.ParseAndVerify(testToken(t, key, header, claims)); err != nil {
+			t.Errorf("token parse fail, %v", err)
+		}
+		if n := atomic.LoadInt32(&fetches); n != int32(i+1) {
+			t.Errorf("expected keys expired at clock, got %v fetches", n)
+		}
+		now = now.Add(time.Hour * 2)
+	}
+}
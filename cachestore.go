@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrCacheMiss is returned by a KeyCacheStore which has no keys under the requested key.
+var ErrCacheMiss = errors.New("keys not in cache")
+
+// KeyCacheStore is an external store of fetched keys, e.g. Redis or memcached.
+// Methods may be called asynchronously by multiple go routines.
+type KeyCacheStore interface {
+	// Get returns the keys stored under key and their expiration, or ErrCacheMiss.
+	Get(ctx context.Context, key string) (keys []byte, expires time.Time, err error)
+	// Set stores keys under key, they may be evicted after expires.
+	Set(ctx context.Context, key string, keys []byte, expires time.Time) error
+}
+
+// fileCacheStore is a KeyCacheStore of a single file, keys are ignored.
+type fileCacheStore string
+
+// cacheFileContent is the JSON encoding of a cache file.
+type cacheFileContent struct {
+	Expires time.Time `json:"expires"`
+	Keys    []byte    `json:"keys"`
+}
+
+// Get returns the raw keys and their expiration from the cache file.
+func (f fileCacheStore) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	b, err := os.ReadFile(string(f))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var c cacheFileContent
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, time.Time{}, err
+	}
+	return c.Keys, c.Expires, nil
+}
+
+// Set replaces the cache file, the file is written to a temporary file first
+// so that a concurrent reader never sees a partial file.
+func (f fileCacheStore) Set(ctx context.Context, key string, keys []byte, expires time.Time) error {
+	path := string(f)
+	b, err := json.Marshal(cacheFileContent{Expires: expires, Keys: keys})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
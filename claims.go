@@ -0,0 +1,408 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+)
+
+// unmarshalClaims decodes the JSON claims data into c like json.Unmarshal, but without reflection for the usual claims:
+// strings without escapes, integer times and the known shapes of aud and email_verified.
+// Any other claims, and malformed JSON, are left to json.Unmarshal, which returns the same claims or errors.
+// c must be zero.
+func unmarshalClaims(data []byte, c *Claims) error {
+	d := claimsDecoder{data: string(data)}
+	if d.decode(c) {
+		return nil
+	}
+	*c = Claims{}
+	return json.Unmarshal(data, c)
+}
+
+// unmarshalRegisteredClaims is like unmarshalClaims but only decodes the registered claims iss, sub, aud, exp, iat and nbf,
+// the other claims must be valid JSON but are not decoded.
+func unmarshalRegisteredClaims(data []byte, c *Claims) error {
+	d := claimsDecoder{data: string(data), registered: true}
+	if d.decode(c) {
+		return nil
+	}
+	*c = Claims{}
+	var r struct {
+		ISS string   `json:"iss"`
+		SUB string   `json:"sub"`
+		AUD Audience `json:"aud"`
+		EXP int64    `json:"exp"`
+		IAT int64    `json:"iat"`
+		NBF int64    `json:"nbf"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	*c = Claims{ISS: r.ISS, SUB: r.SUB, AUD: r.AUD, EXP: r.EXP, IAT: r.IAT, NBF: r.NBF}
+	return nil
+}
+
+// claimNames are the JSON names of the fields of Claims.
+var claimNames = []string{"iss", "azp", "aud", "sub", "email", "email_verified", "at_hash", "name", "picture",
+	"given_name", "family_name", "locale", "nonce", "profile", "hd", "auth_time", "iat", "exp", "nbf"}
+
+// registeredClaimNames are the JSON names of the registered claims of Claims.
+var registeredClaimNames = []string{"iss", "sub", "aud", "exp", "iat", "nbf"}
+
+// maxSkipDepth is the nesting depth of unknown claims above which they are left to json.Unmarshal.
+const maxSkipDepth = 64
+
+// claimsDecoder decodes the JSON object data into Claims, its methods report false for input it doesn't handle.
+// The decoded strings are substrings of data, so that decoding allocates data once.
+type claimsDecoder struct {
+	data string
+	i    int
+	// registered only decodes registeredClaimNames
+	registered bool
+}
+
+// decode decodes the whole data into c.
+func (d *claimsDecoder) decode(c *Claims) bool {
+	if !d.consume('{') {
+		return false
+	}
+	if !d.consume('}') {
+		for {
+			key, ok := d.rawString()
+			if !ok || !d.consume(':') || !d.field(c, key) {
+				return false
+			}
+			if d.consume(',') {
+				continue
+			}
+			if !d.consume('}') {
+				return false
+			}
+			break
+		}
+	}
+	d.space()
+	return d.i == len(d.data)
+}
+
+// field decodes the value of the claim key into c.
+func (d *claimsDecoder) field(c *Claims, key string) bool {
+	if d.registered {
+		switch key {
+		case "iss", "sub", "aud", "exp", "iat", "nbf":
+		default:
+			return d.unknown(key, registeredClaimNames)
+		}
+	}
+	switch key {
+	case "iss":
+		return d.stringField(&c.ISS)
+	case "azp":
+		return d.stringField(&c.AZP)
+	case "aud":
+		return d.audience(&c.AUD)
+	case "sub":
+		return d.stringField(&c.SUB)
+	case "email":
+		return d.stringField(&c.Email)
+	case "email_verified":
+		return d.bool(&c.EmailVerified)
+	case "at_hash":
+		return d.stringField(&c.ATHash)
+	case "name":
+		return d.stringField(&c.Name)
+	case "picture":
+		return d.stringField(&c.Picture)
+	case "given_name":
+		return d.stringField(&c.GivenName)
+	case "family_name":
+		return d.stringField(&c.FamilyName)
+	case "locale":
+		return d.stringField(&c.Locale)
+	case "nonce":
+		return d.stringField(&c.Nonce)
+	case "profile":
+		return d.stringField(&c.Profile)
+	case "hd":
+		return d.stringField(&c.HD)
+	case "auth_time":
+		return d.intField(&c.AuthTime)
+	case "iat":
+		return d.intField(&c.IAT)
+	case "exp":
+		return d.intField(&c.EXP)
+	case "nbf":
+		return d.intField(&c.NBF)
+	}
+	return d.unknown(key, claimNames)
+}
+
+// unknown skips the value of the claim key, which is not one of names.
+func (d *claimsDecoder) unknown(key string, names []string) bool {
+	// json.Unmarshal matches the names case-insensitively
+	for _, name := range names {
+		if key != name && strings.EqualFold(key, name) {
+			return false
+		}
+	}
+	return d.skip(0)
+}
+
+// space skips white space.
+func (d *claimsDecoder) space() {
+	for d.i < len(d.data) {
+		switch d.data[d.i] {
+		case ' ', '\t', '\n', '\r':
+			d.i++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips white space and b, and reports whether b was next.
+func (d *claimsDecoder) consume(b byte) bool {
+	d.space()
+	if d.i < len(d.data) && d.data[d.i] == b {
+		d.i++
+		return true
+	}
+	return false
+}
+
+// literal skips white space and lit, and reports whether lit was next.
+func (d *claimsDecoder) literal(lit string) bool {
+	d.space()
+	if len(d.data)-d.i >= len(lit) && d.data[d.i:d.i+len(lit)] == lit {
+		d.i += len(lit)
+		return true
+	}
+	return false
+}
+
+// rawString returns the contents of a string without escapes and with valid UTF-8.
+func (d *claimsDecoder) rawString() (string, bool) {
+	if !d.consume('"') {
+		return "", false
+	}
+	ascii := true
+	for j := d.i; j < len(d.data); j++ {
+		switch c := d.data[j]; {
+		case c == '"':
+			s := d.data[d.i:j]
+			if !ascii && !utf8.ValidString(s) {
+				return "", false
+			}
+			d.i = j + 1
+			return s, true
+		case c == '\\' || c < 0x20:
+			return "", false
+		case c >= utf8.RuneSelf:
+			ascii = false
+		}
+	}
+	return "", false
+}
+
+// stringField decodes a string or null into s.
+func (d *claimsDecoder) stringField(s *string) bool {
+	if d.literal("null") {
+		return true
+	}
+	v, ok := d.rawString()
+	if ok {
+		*s = v
+	}
+	return ok
+}
+
+// intField decodes an integer or null into n.
+func (d *claimsDecoder) intField(n *int64) bool {
+	if d.literal("null") {
+		return true
+	}
+	d.space()
+	neg := d.i < len(d.data) && d.data[d.i] == '-'
+	if neg {
+		d.i++
+	}
+	start := d.i
+	var v int64
+	for ; d.i < len(d.data) && d.data[d.i] >= '0' && d.data[d.i] <= '9'; d.i++ {
+		digit := int64(d.data[d.i] - '0')
+		if v > (1<<63-1-digit)/10 {
+			return false
+		}
+		v = v*10 + digit
+	}
+	if d.i == start || (d.data[start] == '0' && d.i-start > 1) {
+		return false
+	}
+	if neg {
+		v = -v
+	}
+	*n = v
+	return true
+}
+
+// audience decodes a string or an array of strings into a, as Audience.UnmarshalJSON.
+func (d *claimsDecoder) audience(a *Audience) bool {
+	if !d.consume('[') {
+		v, ok := d.rawString()
+		if ok {
+			*a = Audience{v}
+		}
+		return ok
+	}
+	auds := Audience{}
+	if d.consume(']') {
+		*a = auds
+		return true
+	}
+	for {
+		v, ok := d.rawString()
+		if !ok {
+			return false
+		}
+		auds = append(auds, v)
+		if d.consume(',') {
+			continue
+		}
+		if !d.consume(']') {
+			return false
+		}
+		*a = auds
+		return true
+	}
+}
+
+// bool decodes a boolean, a "true" or "false" string, or null into b, as Bool.UnmarshalJSON.
+func (d *claimsDecoder) bool(b *Bool) bool {
+	switch {
+	case d.literal("true"), d.literal(`"true"`):
+		*b = true
+	case d.literal("false"), d.literal(`"false"`), d.literal("null"):
+		*b = false
+	default:
+		return false
+	}
+	return true
+}
+
+// skip skips a valid JSON value nested depth levels deep.
+func (d *claimsDecoder) skip(depth int) bool {
+	if depth > maxSkipDepth {
+		return false
+	}
+	d.space()
+	if d.i >= len(d.data) {
+		return false
+	}
+	switch c := d.data[d.i]; {
+	case c == '"':
+		return d.skipString()
+	case c == '{':
+		d.i++
+		if d.consume('}') {
+			return true
+		}
+		for {
+			d.space()
+			if !d.skipString() || !d.consume(':') || !d.skip(depth+1) {
+				return false
+			}
+			if !d.consume(',') {
+				return d.consume('}')
+			}
+		}
+	case c == '[':
+		d.i++
+		if d.consume(']') {
+			return true
+		}
+		for {
+			if !d.skip(depth + 1) {
+				return false
+			}
+			if !d.consume(',') {
+				return d.consume(']')
+			}
+		}
+	case c == '-' || (c >= '0' && c <= '9'):
+		return d.skipNumber()
+	}
+	return d.literal("true") || d.literal("false") || d.literal("null")
+}
+
+// skipString skips a string, which may have escapes.
+func (d *claimsDecoder) skipString() bool {
+	if d.i >= len(d.data) || d.data[d.i] != '"' {
+		return false
+	}
+	for j := d.i + 1; j < len(d.data); j++ {
+		switch c := d.data[j]; {
+		case c == '"':
+			d.i = j + 1
+			return true
+		case c < 0x20:
+			return false
+		case c == '\\':
+			if j++; j >= len(d.data) {
+				return false
+			}
+			switch d.data[j] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				if j+4 >= len(d.data) {
+					return false
+				}
+				for _, h := range []byte(d.data[j+1 : j+5]) {
+					if !(h >= '0' && h <= '9' || h >= 'a' && h <= 'f' || h >= 'A' && h <= 'F') {
+						return false
+					}
+				}
+				j += 4
+			default:
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// skipNumber skips a number: an optional minus, an integer without leading zeros, an optional fraction and exponent.
+func (d *claimsDecoder) skipNumber() bool {
+	if d.i < len(d.data) && d.data[d.i] == '-' {
+		d.i++
+	}
+	if d.i < len(d.data) && d.data[d.i] == '0' {
+		d.i++
+	} else if !d.digits() {
+		return false
+	}
+	if d.i < len(d.data) && d.data[d.i] == '.' {
+		d.i++
+		if !d.digits() {
+			return false
+		}
+	}
+	if d.i < len(d.data) && (d.data[d.i] == 'e' || d.data[d.i] == 'E') {
+		d.i++
+		if d.i < len(d.data) && (d.data[d.i] == '+' || d.data[d.i] == '-') {
+			d.i++
+		}
+		if !d.digits() {
+			return false
+		}
+	}
+	return true
+}
+
+// digits skips one or more digits.
+func (d *claimsDecoder) digits() bool {
+	start := d.i
+	for d.i < len(d.data) && d.data[d.i] >= '0' && d.data[d.i] <= '9' {
+		d.i++
+	}
+	return d.i > start
+}
@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalClaims(t *testing.T) {
+	tests := []struct {
+		claims string
+		fast   bool // decoded without json.Unmarshal
+	}{
+		{claims: `{"iss":"https://accounts.google.com","azp":"1234","aud":"1234","sub":"1234","email":"1234@gmail.com","email_verified":true,"at_hash":"1234","name":"Foo Bar","picture":"https://lh3.googleusercontent.com/a-/1234","given_name":"Foo","family_name":"Bar","locale":"en","nonce":"n","profile":"p","hd":"example.com","auth_time":1646617000,"iat":1646617014,"exp":2646620614}`, fast: true},
+		{claims: " {\n\t\"iss\" : \"a\" ,\r\n \"exp\" : -5 } ", fast: true},
+		{claims: `{}`, fast: true},
+		{claims: `{"aud":["a","b"]}`, fast: true},
+		{claims: `{"aud":[]}`, fast: true},
+		{claims: `{"aud":null}`},
+		{claims: `{"aud":["a",null]}`},
+		{claims: `{"aud":1}`},
+		{claims: `{"email_verified":"true"}`, fast: true},
+		{claims: `{"email_verified":"false"}`, fast: true},
+		{claims: `{"email_verified":null}`, fast: true},
+		{claims: `{"email_verified":1}`},
+		{claims: `{"email_verified":"yes"}`},
+		{claims: `{"name":"José","picture":"https:\/\/example.com"}`},
+		{claims: `{"name":"José"}`, fast: true},
+		{claims: "{\"name\":\"\xff\"}"},
+		{claims: `{"name":null,"exp":null}`, fast: true},
+		{claims: `{"name":1}`},
+		{claims: `{"ISS":"a"}`},
+		{claims: `{"ſub":"a"}`},
+		{claims: `{"custom":{"a":[1,-2.5e3,"A\n",true,false,null,{}],"b":[]},"iss":"a"}`, fast: true},
+		{claims: "{\"custom\":\"\xff\"}", fast: true},
+		{claims: `{"exp":1.5}`},
+		{claims: `{"exp":1e9}`},
+		{claims: `{"exp":012}`},
+		{claims: `{"exp":- 5}`},
+		{claims: `{"exp":99999999999999999999}`},
+		{claims: `{"exp":"1"}`},
+		{claims: `{"iss":"a","iss":"b","aud":["a"],"aud":"b"}`, fast: true},
+		{claims: `{"custom":01}`},
+		{claims: `{"custom":"\x"}`},
+		{claims: `{"custom":"\u12"}`},
+		{claims: "{\"custom\":\"\t\"}"},
+		{claims: `{"custom":tru}`},
+		{claims: `{"custom":[1,]}`},
+		{claims: `{"custom":` + strings.Repeat("[", 100) + strings.Repeat("]", 100) + `}`},
+		{claims: `{"iss":"a",}`},
+		{claims: `{"iss":"a"} x`},
+		{claims: `{"iss" "a"}`},
+		{claims: `{"iss":"a"`},
+		{claims: `[]`},
+		{claims: `null`},
+		{claims: ``},
+	}
+	for _, v := range tests {
+		var expected Claims
+		expectedErr := json.Unmarshal([]byte(v.claims), &expected)
+		var c Claims
+		err := unmarshalClaims([]byte(v.claims), &c)
+		if (err == nil) != (expectedErr == nil) || !reflect.DeepEqual(c, expected) {
+			t.Errorf("%v decoded to %+v, %v, expected %+v, %v", v.claims, c, err, expected, expectedErr)
+		}
+		d := claimsDecoder{data: v.claims}
+		if fast := d.decode(&Claims{}); fast != v.fast {
+			t.Errorf("%v decoded without json.Unmarshal %v, expected %v", v.claims, fast, v.fast)
+		}
+	}
+}
+
+func TestUnmarshalRegisteredClaims(t *testing.T) {
+	for _, v := range []struct {
+		claims   string
+		expected Claims
+		err      bool
+	}{
+		{claims: `{"iss":"a","sub":"b","aud":["c"],"exp":1,"iat":2,"email":"d","email_verified":1}`, expected: Claims{ISS: "a", SUB: "b", AUD: Audience{"c"}, EXP: 1, IAT: 2}},
+		{claims: `{"ISS":"a","name":"A"}`, expected: Claims{ISS: "a"}},
+		{claims: `{"exp":1.5}`, err: true},
+		{claims: `{"email":}`, err: true},
+	} {
+		var c Claims
+		err := unmarshalRegisteredClaims([]byte(v.claims), &c)
+		if (err != nil) != v.err || !reflect.DeepEqual(c, v.expected) {
+			t.Errorf("%v decoded to %+v, %v, expected %+v", v.claims, c, err, v.expected)
+		}
+	}
+}
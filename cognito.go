@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+)
+
+// cognitoIssuer returns the issuer of the tokens of an Amazon Cognito user pool.
+func cognitoIssuer(region, userPoolID string) string {
+	return fmt.Sprintf("https://cognito-idp.%v.amazonaws.com/%v", region, userPoolID)
+}
+
+// NewCognitoKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign the tokens of an Amazon Cognito user pool.
+func NewCognitoKeyFetcher(region, userPoolID string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(cognitoIssuer(region, userPoolID)+"/.well-known/jwks.json", opts...)
+}
+
+// NewCognitoVerifier returns a Verifier which parses and verifies the ID tokens of an Amazon Cognito user pool issued to clientID.
+// Tokens are verified with the keys of NewCognitoKeyFetcher, their issuer must be https://cognito-idp.<region>.amazonaws.com/<userPoolID>
+// and their token_use claim id.
+func NewCognitoVerifier(region, userPoolID, clientID string, opts ...Option) (*Verifier, error) {
+	return newCognitoVerifier(context.Background(), NewCognitoKeyFetcher(region, userPoolID), region, userPoolID, clientID, "id", opts)
+}
+
+// NewCognitoAccessVerifier is like NewCognitoVerifier for access tokens, which have no aud claim.
+// Their token_use claim must be access and their client_id claim clientID.
+func NewCognitoAccessVerifier(region, userPoolID, clientID string, opts ...Option) (*Verifier, error) {
+	return newCognitoVerifier(context.Background(), NewCognitoKeyFetcher(region, userPoolID), region, userPoolID, clientID, "access", opts)
+}
+
+func newCognitoVerifier(ctx context.Context, keyFetcher KeyFetcher, region, userPoolID, clientID, tokenUse string, opts []Option) (*Verifier, error) {
+	if region == "" || userPoolID == "" {
+		return nil, fmt.Errorf("empty Cognito region or user pool ID")
+	}
+	opts = append([]Option{withCognitoAudience(clientID, tokenUse)}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, cognitoIssuer(region, userPoolID), clientID, opts)
+}
+
+// withCognitoAudience checks the token_use claim, and the aud claim of id tokens or the client_id claim of access tokens.
+func withCognitoAudience(clientID, tokenUse string) Option {
+	return func(v *Verifier) {
+		v.checkAudience = func(token *JWT) error {
+			var claims struct {
+				TokenUse string `json:"token_use"`
+				ClientID string `json:"client_id"`
+			}
+			if err := token.UnmarshalClaims(&claims); err != nil {
+				return fmt.Errorf("unable to json decode claims, %w", err)
+			}
+			if claims.TokenUse != tokenUse {
+				return fmt.Errorf("%w, expected token_use %v, but token_use is %v", ErrInvalidAudience, tokenUse, claims.TokenUse)
+			}
+			if tokenUse == "access" && claims.ClientID != clientID || tokenUse == "id" && !token.Claims.AUD.Contains(clientID) {
+				return fmt.Errorf("%w, client ID does not match", ErrInvalidAudience)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCognitoVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	const issuer = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_abc"
+
+	idToken := testToken(t, key, nil, map[string]interface{}{"iss": issuer, "aud": "client", "token_use": "id"})
+	accessToken := testToken(t, key, nil, map[string]interface{}{"iss": issuer, "aud": nil, "client_id": "client", "token_use": "access"})
+	tests := []struct {
+		name     string
+		tokenUse string
+		token    string
+		valid    bool
+	}{
+		{"id token", "id", idToken, true},
+		{"access token as id token", "id", accessToken, false},
+		{"access token", "access", accessToken, true},
+		{"id token as access token", "access", idToken, false},
+		{"other client", "access", testToken(t, key, nil, map[string]interface{}{"iss": issuer, "aud": nil, "client_id": "other", "token_use": "access"}), false},
+		{"other pool", "id", testToken(t, key, nil, map[string]interface{}{"iss": issuer + "x", "aud": "client", "token_use": "id"}), false},
+		{"no token_use", "id", testToken(t, key, nil, map[string]interface{}{"iss": issuer, "aud": "client"}), false},
+	}
+	for _, test := range tests {
+		ver, err := newCognitoVerifier(context.Background(), keyGetterFunc(jwks), "us-east-1", "us-east-1_abc", "client", test.tokenUse, nil)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		_, err = ver.ParseAndVerify(test.token)
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	if NewCognitoKeyFetcher("us-east-1", "us-east-1_abc").URL() != issuer+"/.well-known/jwks.json" {
+		t.Errorf("unexpected key URL %v", NewCognitoKeyFetcher("us-east-1", "us-east-1_abc").URL())
+	}
+}
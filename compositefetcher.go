@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompositeKeyFetcher is a KeyFetcher which tries multiple KeyFetchers in order, e.g. a primary url,
+// a mirror url and a local snapshot file, and returns the first successful result.
+type CompositeKeyFetcher struct {
+	fetchers []KeyFetcher
+
+	// mu guards last, the index of the last successful fetcher
+	mu   sync.Mutex
+	last int
+}
+
+// NewCompositeKeyFetcher returns a CompositeKeyFetcher which tries fetchers in the given order.
+func NewCompositeKeyFetcher(fetchers ...KeyFetcher) *CompositeKeyFetcher {
+	return &CompositeKeyFetcher{fetchers: fetchers, last: -1}
+}
+
+// Fetch returns the result of the first fetcher which succeeds. ErrNotModified is returned only if
+// the previous Fetch succeeded with the same fetcher, since the cached keys may be of another one.
+// If all fetchers fail the error wraps their FetchErrors. Fetch stops when ctx is done,
+// the error then matches ctx.Err() and wraps the errors of the fetchers tried.
+func (c *CompositeKeyFetcher) Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	var errs FetchErrors
+	for i, f := range c.fetchers {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if len(errs) == 0 {
+				return nil, time.Now(), ctxErr
+			}
+			return nil, time.Now(), wrapSentinel(ctxErr, ", key fetch canceled - ", errs)
+		}
+		r, expires, err = f.Fetch(ctx)
+		if err != nil && !(errors.Is(err, ErrNotModified) && r != nil) {
+			errs = append(errs, err)
+			continue
+		}
+
+		c.mu.Lock()
+		if c.last != i {
+			err = nil
+		}
+		c.last = i
+		c.mu.Unlock()
+		return r, expires, err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, time.Now(), wrapSentinel(ctxErr, ", key fetch canceled - ", errs)
+	}
+	return nil, time.Now(), fmt.Errorf("all key fetchers failed - %w", errs)
+}
+
+// FetchErrors are the errors of the fetchers of a CompositeKeyFetcher, in order.
+type FetchErrors []error
+
+// Error returns the messages of the errors, separated by semicolons.
+func (e FetchErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (e FetchErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors which matches target.
+func (e FetchErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompositeKeyFetcher(t *testing.T) {
+	failing := KeyFetcherFunc(func() (io.ReadCloser, time.Time, error) {
+		return nil, time.Now(), errors.New("unreachable")
+	})
+	notModified := KeyFetcherFunc(func() (io.ReadCloser, time.Time, error) {
+		return io.NopCloser(strings.NewReader(validKey)), time.Now().Add(time.Hour), ErrNotModified
+	})
+
+	c := NewCompositeKeyFetcher(failing, notModified)
+	r, _, err := c.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+	if _, _, err := c.Fetch(context.Background()); !errors.Is(err, ErrNotModified) {
+		t.Errorf("expected ErrNotModified from the same fetcher, got %v", err)
+	}
+
+	if _, _, err := NewCompositeKeyFetcher(failing, failing).Fetch(context.Background()); err == nil {
+		t.Errorf("failing fetchers not throwing error")
+	}
+
+	unavailable := KeyFetcherFunc(func() (io.ReadCloser, time.Time, error) {
+		return nil, time.Now(), &FetchError{URL: "https://example.com", StatusCode: 503}
+	})
+	_, _, err = NewCompositeKeyFetcher(failing, unavailable).Fetch(context.Background())
+	var fetchErr *FetchError
+	var errs FetchErrors
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != 503 || !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("expected the errors of the fetchers, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	canceling := KeyFetcherFunc(func() (io.ReadCloser, time.Time, error) {
+		calls++
+		cancel()
+		return nil, time.Now(), &FetchError{URL: "https://example.com", StatusCode: 503}
+	})
+	_, _, err = NewCompositeKeyFetcher(canceling, canceling).Fetch(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &fetchErr) || calls != 1 {
+		t.Errorf("expected canceled error after one fetcher, got %v after %v calls", err, calls)
+	}
+	if _, _, err := NewCompositeKeyFetcher(canceling).Fetch(ctx); !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected context error without fetching, got %v", err)
+	}
+
+	ver, err := NewVerifierContext(context.Background(), NewCompositeKeyFetcher(failing, keyGetterFunc(validKey)), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+}
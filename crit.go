@@ -0,0 +1,37 @@
+package jwt
+
+import "fmt"
+
+// registeredHeaders are the header parameters defined by RFC 7515 and RFC 7516, which must not be listed by crit.
+var registeredHeaders = []string{"alg", "jku", "jwk", "kid", "x5u", "x5c", "x5t", "x5t#S256", "typ", "cty", "crit", "enc", "zip"}
+
+// WithCriticalHeaders accepts tokens whose crit header lists the extension header parameters names,
+// which the application understands and processes, e.g. with JWT.UnmarshalHeader after verification.
+// Tokens listing any other parameter in crit are rejected as required by RFC 7515.
+func WithCriticalHeaders(names ...string) Option {
+	return func(v *Verifier) {
+		v.criticalHeaders = append(v.criticalHeaders, names...)
+	}
+}
+
+// checkCritical returns a ValidationError unless crit, the crit header of a token, is absent or a non-empty list
+// of extension parameters understood by the application or understood, the parameters the caller processes.
+func (v *Verifier) checkCritical(crit []string, understood ...string) error {
+	if crit == nil {
+		return nil
+	}
+	if len(crit) == 0 {
+		return &ValidationError{Kind: KindMalformed, Claim: "crit", Err: fmt.Errorf("%w, empty crit header", ErrMalformed)}
+	}
+	for _, name := range crit {
+		if containsString(registeredHeaders, name) {
+			return &ValidationError{Kind: KindMalformed, Claim: "crit", Actual: name,
+				Err: fmt.Errorf("%w, registered header parameter %v is critical", ErrMalformed, name)}
+		}
+		if !containsString(v.criticalHeaders, name) && !containsString(understood, name) {
+			return &ValidationError{Kind: KindMalformed, Claim: "crit", Actual: name,
+				Err: fmt.Errorf("%w, unsupported critical header parameter %v", ErrMalformed, name)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCriticalHeaders(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithCriticalHeaders("https://example.com/tenant"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	token, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"crit": []string{"https://example.com/tenant"}, "https://example.com/tenant": "t1"}, nil))
+	if err != nil {
+		t.Fatalf("token parse fail, %v", err)
+	}
+	var header struct {
+		Tenant string `json:"https://example.com/tenant"`
+	}
+	if err := token.UnmarshalHeader(&header); err != nil || header.Tenant != "t1" {
+		t.Errorf("unexpected header %+v, %v", header, err)
+	}
+
+	for _, crit := range [][]string{{"exp"}, {}, {"kid"}, {"b64"}, {"https://example.com/tenant", "exp"}} {
+		_, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"crit": crit}, nil))
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Claim != "crit" || !errors.Is(err, ErrMalformed) {
+			t.Errorf("crit %v: expected malformed crit header, got %v", crit, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// VerifyDetached verifies the JWS signature of payload, in compact serialization with a detached, i.e. empty, payload,
+// e.g. the x-jws-signature header of a request signed over its body. The payload is signed as is if the b64 header parameter
+// is false, as defined by RFC 7797, in which case crit must list b64, or base64url encoded otherwise.
+// Other critical header parameters must be registered with WithCriticalHeaders.
+// The protected header is decoded into header if non-nil, e.g. to check the claims some APIs put in the header.
+// Unlike ParseAndVerify, only the signature is verified, a failure is a ValidationError.
+func (v *Verifier) VerifyDetached(ctx context.Context, signature string, payload []byte, header interface{}) error {
+	parts := strings.Split(signature, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return v.formatError(&ValidationError{Kind: KindMalformed, Err: fmt.Errorf("%w, expected a detached signature of 3 parts with an empty payload", ErrMalformed)})
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return v.formatError(&ValidationError{Kind: KindMalformed, Err: wrapSentinel(ErrMalformed, ", decode header - ", err)})
+	}
+	var h struct {
+		Header
+		B64 *bool `json:"b64"`
+	}
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return v.formatError(&ValidationError{Kind: KindMalformed, Err: wrapSentinel(ErrMalformed, ", unmarshal header - ", err)})
+	}
+	if err := v.checkCritical(h.CRIT, "b64"); err != nil {
+		return v.formatError(err)
+	}
+	encoded := h.B64 == nil || *h.B64
+	if !encoded && !containsString(h.CRIT, "b64") {
+		return v.formatError(&ValidationError{Kind: KindMalformed, Claim: "crit", Expected: "b64",
+			Err: fmt.Errorf("%w, b64 header parameter isn't critical", ErrMalformed)})
+	}
+
+	signed := parts[0] + "."
+	if encoded {
+		signed += base64.RawURLEncoding.EncodeToString(payload)
+	} else {
+		signed += string(payload)
+	}
+	if _, err := v.verifyTokenSignature(ctx, signed, parts[2], &JWT{Header: h.Header, Signature: parts[2]}); err != nil {
+		return v.formatError(err)
+	}
+	if header != nil {
+		if err := json.Unmarshal(rawHeader, header); err != nil {
+			return fmt.Errorf("unmarshal header - %w", err)
+		}
+	}
+	return nil
+}
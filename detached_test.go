@@ -0,0 +1,59 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestVerifyDetached(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	payload := []byte(`{"Data":{"Amount":"10.00"}}`)
+	sign := func(header string, signedPayload string) string {
+		protected := base64.RawURLEncoding.EncodeToString([]byte(header))
+		sig, err := key.Sign(rand.Reader, []byte(protected+"."+signedPayload), crypto.Hash(0))
+		if err != nil {
+			t.Fatalf("sign failed, %v", err)
+		}
+		return protected + ".." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+	unencoded := sign(`{"alg":"EdDSA","kid":"test","b64":false,"crit":["b64"],"iat":1700000000}`, string(payload))
+	encoded := sign(`{"alg":"EdDSA","kid":"test"}`, base64.RawURLEncoding.EncodeToString(payload))
+
+	var header struct {
+		IAT int64 `json:"iat"`
+	}
+	if err := ver.VerifyDetached(context.Background(), unencoded, payload, &header); err != nil {
+		t.Fatalf("unencoded payload verify fail, %v", err)
+	}
+	if header.IAT != 1700000000 {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if err := ver.VerifyDetached(context.Background(), encoded, payload, nil); err != nil {
+		t.Errorf("encoded payload verify fail, %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		signature string
+		payload   []byte
+		err       error
+	}{
+		{"other payload", unencoded, []byte(`{"Data":{"Amount":"1000.00"}}`), ErrInvalidSignature},
+		{"b64 not critical", sign(`{"alg":"EdDSA","kid":"test","b64":false}`, string(payload)), payload, ErrMalformed},
+		{"unknown critical", sign(`{"alg":"EdDSA","kid":"test","b64":false,"crit":["b64","exp"]}`, string(payload)), payload, ErrMalformed},
+		{"attached payload", "e30.e30.e30", payload, ErrMalformed},
+	}
+	for _, test := range tests {
+		if err := ver.VerifyDetached(context.Background(), test.signature, test.payload, nil); !errors.Is(err, test.err) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
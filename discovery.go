@@ -0,0 +1,35 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DiscoverKeyFetcher returns an HTTPKeyFetcher which obtains the keys of an OpenID provider,
+// from the jwks_uri of the provider metadata at <issuer>/.well-known/openid-configuration.
+// The metadata is requested, and the keys are fetched, as configured by opts.
+func DiscoverKeyFetcher(ctx context.Context, issuer string, opts ...HTTPOption) (*HTTPKeyFetcher, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	r, _, err := NewHTTPKeyFetcher(issuer+"/.well-known/openid-configuration", opts...).Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch provider metadata - %w", err)
+	}
+	defer r.Close()
+	var metadata struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(r).Decode(&metadata); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("decode provider metadata - %w", err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("provider metadata issuer %v does not match %v", metadata.Issuer, issuer)
+	}
+	if !strings.HasPrefix(metadata.JWKSURI, "https://") {
+		return nil, fmt.Errorf("invalid jwks_uri %v", metadata.JWKSURI)
+	}
+	return NewHTTPKeyFetcher(metadata.JWKSURI, opts...), nil
+}
@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverKeyFetcher(t *testing.T) {
+	var issuer string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth2/default/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer": "%v", "jwks_uri": "%v/v1/keys"}`, issuer, issuer)
+		case "/other/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer": "%v", "jwks_uri": "%v/v1/keys"}`, issuer, issuer)
+		case "/oauth2/default/v1/keys":
+			fmt.Fprint(w, validKey)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	issuer = srv.URL + "/oauth2/default"
+
+	f, err := DiscoverKeyFetcher(context.Background(), issuer+"/", WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatalf("discover failed, %v", err)
+	}
+	if f.URL() != issuer+"/v1/keys" {
+		t.Errorf("unexpected jwks_uri %v", f.URL())
+	}
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+
+	if _, err := DiscoverKeyFetcher(context.Background(), srv.URL+"/other", WithHTTPClient(srv.Client())); err == nil {
+		t.Errorf("issuer mismatch not throwing error")
+	}
+	if _, err := DiscoverKeyFetcher(context.Background(), srv.URL+"/missing", WithHTTPClient(srv.Client()), WithRetry(0, 0, 0)); err == nil {
+		t.Errorf("missing metadata not throwing error")
+	}
+}
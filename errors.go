@@ -0,0 +1,305 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors wrapped by the errors of ParseAndVerify, tested with errors.Is, e.g. to tell an expired token from an invalid one.
+var (
+	// ErrMalformed is wrapped when a token isn't three base64url encoded parts with a JSON header and claims.
+	ErrMalformed = errors.New("malformed token")
+	// ErrInvalidSignature is wrapped when the signature of a token or its alg is invalid.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrKeyNotFound is wrapped when there is no key to verify a token with.
+	ErrKeyNotFound = errors.New("matching key not found")
+	// ErrInvalidIssuer is wrapped when the issuer of a token isn't accepted.
+	ErrInvalidIssuer = errors.New("invalid issuer")
+	// ErrInvalidAudience is wrapped when the audience of a token isn't accepted.
+	ErrInvalidAudience = errors.New("invalid audience")
+	// ErrExpired is wrapped when a token is expired.
+	ErrExpired = errors.New("token expired")
+	// ErrInactive is wrapped when the introspection of a token reports it isn't active, e.g. because it's revoked.
+	ErrInactive = errors.New("token not active")
+)
+
+// sentinelError wraps err with a sentinel error which it matches, as fmt.Errorf wraps only one error before go 1.20.
+type sentinelError struct {
+	sentinel, err error
+}
+
+// wrapSentinel returns an error which matches sentinel and wraps err, with the message "<sentinel><sep><err>".
+func wrapSentinel(sentinel error, sep string, err error) error {
+	return &sentinelError{sentinel: sentinel, err: fmt.Errorf("%v%v%w", sentinel, sep, err)}
+}
+
+func (e *sentinelError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
+
+// ExpiredError is the error of an expired token, which matches ErrExpired,
+// e.g. to tell a token which needs to be refreshed from clock skew between the issuer and the verifier.
+type ExpiredError struct {
+	// Expiry is the exp claim of the token.
+	Expiry time.Time
+	// Since is how long ago the token expired when it was verified.
+	Since time.Duration
+}
+
+// newExpiredError returns the error of a token expired at the unix time exp, verified at now.
+func newExpiredError(exp int64, now time.Time) *ExpiredError {
+	expiry := time.Unix(exp, 0)
+	return &ExpiredError{Expiry: expiry, Since: now.Sub(expiry)}
+}
+
+// Error returns a message with the time since expiry.
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("token expired %v ago", e.Since.Round(time.Second))
+}
+
+// Is reports whether target is ErrExpired.
+func (e *ExpiredError) Is(target error) bool {
+	return target == ErrExpired
+}
+
+// Kind is the kind of check a token failed.
+type Kind int
+
+// The kinds of a ValidationError.
+const (
+	KindMalformed Kind = iota + 1
+	KindInvalidSignature
+	KindKeyNotFound
+	KindInvalidIssuer
+	KindInvalidAudience
+	KindExpired
+	KindIssuedInFuture
+	// KindInvalidClaim is the kind of failed claim checks of presets and options, e.g. WithOktaClientIDs.
+	KindInvalidClaim
+	// KindInactive is the kind of tokens which introspection reports as not active.
+	KindInactive
+	// KindNotYetValid is the kind of tokens whose nbf claim is in the future.
+	KindNotYetValid
+)
+
+var kindCodes = map[Kind]string{
+	KindMalformed:        "token_malformed",
+	KindInvalidSignature: "signature_invalid",
+	KindKeyNotFound:      "key_not_found",
+	KindInvalidIssuer:    "iss_mismatch",
+	KindInvalidAudience:  "aud_mismatch",
+	KindExpired:          "token_expired",
+	KindIssuedInFuture:   "iat_in_future",
+	KindInvalidClaim:     "claim_invalid",
+	KindInactive:         "token_inactive",
+	KindNotYetValid:      "nbf_in_future",
+}
+
+var kindNames = map[Kind]string{
+	KindMalformed:        "malformed",
+	KindInvalidSignature: "invalid signature",
+	KindKeyNotFound:      "key not found",
+	KindInvalidIssuer:    "invalid issuer",
+	KindInvalidAudience:  "invalid audience",
+	KindExpired:          "expired",
+	KindIssuedInFuture:   "issued in future",
+	KindInvalidClaim:     "invalid claim",
+	KindInactive:         "inactive",
+	KindNotYetValid:      "not yet valid",
+}
+
+// String returns the name of k.
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Code returns the stable error code of k, e.g. token_expired or aud_mismatch, or invalid_token for an unknown Kind.
+func (k Kind) Code() string {
+	if code, ok := kindCodes[k]; ok {
+		return code
+	}
+	return "invalid_token"
+}
+
+// ValidationError is returned by ParseAndVerify when a token is invalid, retrieved with errors.As.
+// Failures to retrieve the keys or to introspect the token aren't ValidationErrors, but retryable errors.
+type ValidationError struct {
+	Kind Kind
+	// Claim is the name of the offending claim or header parameter, e.g. aud or alg, if any.
+	Claim string
+	// Expected and Actual are the expected and actual values of Claim, if known.
+	Expected, Actual string
+	// KID is the kid header parameter of the token, empty if the token is malformed.
+	KID string
+	// Err is the underlying error, which wraps the sentinel error of Kind, if any.
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the Kind of e.
+func (e *ValidationError) ErrorCode() string {
+	return e.Kind.Code()
+}
+
+// KeyFetchError is returned by ParseAndVerify when the keys to verify a token with can't be retrieved,
+// e.g. when the JWKS endpoint is unavailable.
+type KeyFetchError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *KeyFetchError) Error() string {
+	return "retrieve key - " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *KeyFetchError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns jwks_unavailable.
+func (e *KeyFetchError) ErrorCode() string {
+	return "jwks_unavailable"
+}
+
+// Retryable returns true, the keys may be retrieved later.
+func (e *KeyFetchError) Retryable() bool {
+	return true
+}
+
+// IntrospectionError is returned by ParseAndVerify when a token can't be introspected, e.g. when the introspection endpoint
+// is unavailable. A token which introspection reports as not active or expired fails with a *ValidationError instead.
+type IntrospectionError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *IntrospectionError) Error() string {
+	return "introspect token - " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *IntrospectionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns introspection_unavailable.
+func (e *IntrospectionError) ErrorCode() string {
+	return "introspection_unavailable"
+}
+
+// Retryable returns true, the token may be introspected later.
+func (e *IntrospectionError) Retryable() bool {
+	return true
+}
+
+// IsRetryable reports whether err is an infrastructure failure, like *KeyFetchError and *IntrospectionError, after which
+// the verification may succeed if retried, e.g. to respond 503 Service Unavailable instead of 401 Unauthorized.
+// Other errors are permanent, the token is invalid.
+func IsRetryable(err error) bool {
+	var r interface{ Retryable() bool }
+	return errors.As(err, &r) && r.Retryable()
+}
+
+// ErrorCode returns the stable, machine-readable code of a verification error, e.g. for API error bodies and metric labels.
+// The code is that of the first error in the chain of err with an ErrorCode method,
+// like *ValidationError and *KeyFetchError, invalid_token for other errors and "" for a nil err.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coder interface{ ErrorCode() string }
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return "invalid_token"
+}
+
+// kindOf returns the Kind of the sentinel error wrapped by err, or 0 if it doesn't wrap one.
+func kindOf(err error) Kind {
+	for _, k := range []struct {
+		err  error
+		kind Kind
+	}{
+		{ErrMalformed, KindMalformed},
+		{ErrInvalidSignature, KindInvalidSignature},
+		{ErrKeyNotFound, KindKeyNotFound},
+		{ErrInvalidIssuer, KindInvalidIssuer},
+		{ErrInvalidAudience, KindInvalidAudience},
+		{ErrExpired, KindExpired},
+		{ErrInactive, KindInactive},
+	} {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return 0
+}
+
+// asValidationError returns err as a *ValidationError of the token with kid, of kind unless err wraps a sentinel error.
+// err is returned if it already is a *ValidationError.
+func asValidationError(err error, kind Kind, kid string) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return err
+	}
+	if k := kindOf(err); k != 0 {
+		kind = k
+	}
+	return &ValidationError{Kind: kind, KID: kid, Err: err}
+}
+
+// ValidationErrors are all failures of an invalid token, as returned by Inspect.
+// errors.Is and errors.As match any of the errors.
+type ValidationErrors []error
+
+// Error returns the messages of the errors, separated by semicolons.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors which matches target.
+func (e ValidationErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
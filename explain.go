@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Report is the result of every check Explain performed on a token.
+type Report struct {
+	// Token is the parsed token, nil if it's malformed. Its claims are unverified unless the report is valid.
+	Token *JWT
+	// KeyThumbprint is the RFC 7638 thumbprint of the key which verified the signature, empty if none did.
+	KeyThumbprint string
+	// Checks are the checks performed, in order.
+	Checks []CheckResult
+}
+
+// CheckResult is the result of a check of a Report.
+type CheckResult struct {
+	// Name is the name of the check: decrypt for WithDecryption, parse, crit for tokens with a crit header, signature,
+	// iss, aud, exp, iat, nbf, claim for the checks of presets like NewFirebaseVerifier, or active for WithIntrospection.
+	Name string
+	// Err is the failure of the check, nil if it passed.
+	Err error
+}
+
+// Valid reports whether every check passed.
+func (r *Report) Valid() bool {
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return len(r.Checks) > 0
+}
+
+// String returns the checks, one per line, with the key ID, algorithm and key which verified the signature.
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		result := "ok"
+		if c.Err != nil {
+			result = "failed - " + c.Err.Error()
+		}
+		fmt.Fprintf(&b, "%v: %v", c.Name, result)
+		if c.Name == "signature" && r.Token != nil {
+			fmt.Fprintf(&b, " (kid %q, alg %q", r.Token.Header.KID, r.Token.Header.ALG)
+			if r.KeyThumbprint != "" {
+				fmt.Fprintf(&b, ", key %v", r.KeyThumbprint)
+			}
+			b.WriteString(")")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Explain runs every check of ParseAndVerifyContext on tokenString and reports the result of each,
+// e.g. to debug the integration of a client. Unlike ParseAndVerifyContext, it doesn't call hooks nor record metrics.
+// The errors are redacted unless WithDebugErrors is set.
+func (v *Verifier) Explain(ctx context.Context, tokenString string) *Report {
+	r := &Report{}
+	v.verify(ctx, tokenString, true, r)
+	return r
+}
@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifierExplain(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	ctx := context.Background()
+
+	r := ver.Explain(ctx, testToken(t, key, nil, nil))
+	if !r.Valid() || r.KeyThumbprint == "" {
+		t.Errorf("valid token not explained as valid\n%v", r)
+	}
+	names := make([]string, len(r.Checks))
+	for i, c := range r.Checks {
+		names[i] = c.Name
+	}
+	if !equalStrings(names, []string{"parse", "signature", "iss", "aud", "exp", "iat", "nbf"}) {
+		t.Errorf("unexpected checks %v", names)
+	}
+
+	r = ver.Explain(ctx, testToken(t, key, nil, map[string]interface{}{"aud": "other", "exp": time.Now().Add(-time.Minute).Unix()}))
+	if r.Valid() {
+		t.Errorf("invalid token explained as valid")
+	}
+	for _, c := range r.Checks {
+		failed := c.Name == "aud" || c.Name == "exp"
+		if (c.Err != nil) != failed {
+			t.Errorf("unexpected result of check %v, %v", c.Name, c.Err)
+		}
+	}
+	if s := r.String(); !strings.Contains(s, "signature: ok (kid \"test\", alg \"EdDSA\", key ") || !strings.Contains(s, "aud: failed - ") {
+		t.Errorf("unexpected report\n%v", s)
+	}
+
+	r = ver.Explain(ctx, testToken(t, key, nil, map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()}))
+	if last := r.Checks[len(r.Checks)-1]; r.Valid() || last.Name != "nbf" || last.Err == nil {
+		t.Errorf("token not yet valid not explained as failed nbf check\n%v", r)
+	}
+
+	r = ver.Explain(ctx, "malformed")
+	if r.Valid() || r.Token != nil || len(r.Checks) != 1 || !errors.Is(r.Checks[0].Err, ErrMalformed) {
+		t.Errorf("unexpected report of malformed token\n%v", r)
+	}
+}
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// WithExpvar publishes counters of the Verifier under the expvar Map namespace, e.g. served by /debug/vars:
+// verified, the number of verified tokens, failures, the number of failed verifications by ErrorCode,
+// key_fetches and key_fetch_errors, and last_key_refresh, the time of the last successful key fetch.
+// Verifiers with the same namespace share the counters. Like expvar.Publish, it panics if other code published namespace.
+func WithExpvar(namespace string) Option {
+	return WithMetrics(newExpvarRecorder(namespace))
+}
+
+var (
+	expvarMu sync.Mutex
+	// expvarRecorders maps the published namespaces to their recorder
+	expvarRecorders = make(map[string]*expvarRecorder)
+)
+
+// expvarRecorder is a MetricsRecorder of expvar counters.
+type expvarRecorder struct {
+	verified       expvar.Int
+	failures       expvar.Map
+	fetches        expvar.Int
+	fetchErrors    expvar.Int
+	lastKeyRefresh expvar.String
+}
+
+// newExpvarRecorder returns the expvarRecorder of namespace, which is published if it isn't yet.
+func newExpvarRecorder(namespace string) *expvarRecorder {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if r, ok := expvarRecorders[namespace]; ok {
+		return r
+	}
+	r := &expvarRecorder{}
+	r.failures.Init()
+	m := expvar.NewMap(namespace)
+	m.Set("verified", &r.verified)
+	m.Set("failures", &r.failures)
+	m.Set("key_fetches", &r.fetches)
+	m.Set("key_fetch_errors", &r.fetchErrors)
+	m.Set("last_key_refresh", &r.lastKeyRefresh)
+	expvarRecorders[namespace] = r
+	return r
+}
+
+func (r *expvarRecorder) Verification(result string, duration time.Duration) {
+	if result == "ok" {
+		r.verified.Add(1)
+		return
+	}
+	r.failures.Add(result, 1)
+}
+
+func (r *expvarRecorder) KeyFetch(duration time.Duration, err error) {
+	r.fetches.Add(1)
+	if err != nil {
+		r.fetchErrors.Add(1)
+		return
+	}
+	r.lastKeyRefresh.Set(time.Now().UTC().Format(time.RFC3339))
+}
+
+func (r *expvarRecorder) CacheLookup(hit bool) {}
@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestWithExpvar(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	rec := &countingRecorder{verifications: make(map[string]int)}
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithExpvar("jwt_test"), WithMetrics(rec))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})); err == nil {
+		t.Errorf("expired token not throwing error")
+	}
+
+	vars, ok := expvar.Get("jwt_test").(*expvar.Map)
+	if !ok {
+		t.Fatalf("expvar namespace not published")
+	}
+	if got := vars.Get("verified").String(); got != "1" {
+		t.Errorf("unexpected verified %v", got)
+	}
+	if got := vars.Get("failures").String(); got != `{"token_expired": 1}` {
+		t.Errorf("unexpected failures %v", got)
+	}
+	if got := vars.Get("last_key_refresh").String(); got == `""` {
+		t.Errorf("last key refresh not set")
+	}
+	if rec.verifications["ok"] != 1 {
+		t.Errorf("recorder not chained with expvar, %v", rec.verifications)
+	}
+	if newExpvarRecorder("jwt_test") != ver.cacheConfig.metrics.(multiRecorder)[0] {
+		t.Errorf("expvar namespace not shared")
+	}
+}
@@ -0,0 +1,383 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const googleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// defaultKeyFetcher is used by DefaultKeyFetcher and DefaultKeyFetcherContext.
+var defaultKeyFetcher = NewGoogleKeyFetcher()
+
+// DefaultKeyFetcher does an http request to obtain the google public certificates, the request times out after 10 seconds.
+// Failed requests are retried twice with exponential backoff.
+// returns the response body and its expiration according to the caching headers.
+func DefaultKeyFetcher() (r io.ReadCloser, expires time.Time, err error) {
+	return DefaultKeyFetcherContext(context.Background())
+}
+
+// DefaultKeyFetcherContext is like DefaultKeyFetcher, the request is canceled if ctx is done.
+func DefaultKeyFetcherContext(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	return defaultKeyFetcher.Fetch(ctx)
+}
+
+// HTTPKeyFetcher is a KeyFetcher which obtains the keys with an http request.
+type HTTPKeyFetcher struct {
+	url        string
+	client     *http.Client
+	header     http.Header
+	token      AccessTokenFunc
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	defaultTTL time.Duration
+	maxSize    int64
+	log        logFunc
+	tracer     Tracer
+
+	// mu guards the validators and body of the last successful response, used for conditional requests
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// HTTPOption configures an HTTPKeyFetcher.
+type HTTPOption func(*HTTPKeyFetcher)
+
+// WithRetry retries a failed request up to retries times. The wait before a retry is doubled with every attempt,
+// starting at backoff and up to maxBackoff, and is randomized by up to half its value.
+// Retries stop when the context passed to Fetch is done, the error then matches the context error and wraps the last failure.
+func WithRetry(retries int, backoff, maxBackoff time.Duration) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.retries = retries
+		f.backoff = backoff
+		f.maxBackoff = maxBackoff
+	}
+}
+
+// WithHTTPClient sets the client used for requests, the default is http.DefaultClient.
+// Use it for custom transports, proxies or certificate authorities.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.client = client
+	}
+}
+
+// AccessTokenFunc returns an access token, e.g. an OAuth 2.0 bearer token. It may be called concurrently.
+type AccessTokenFunc func(ctx context.Context) (token string, err error)
+
+// WithHeader adds a header to every request, e.g. an API key for a protected key endpoint.
+func WithHeader(key, value string) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		if f.header == nil {
+			f.header = make(http.Header)
+		}
+		f.header.Add(key, value)
+	}
+}
+
+// WithBearerToken authorizes every request with a bearer token obtained from token.
+func WithBearerToken(token AccessTokenFunc) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.token = token
+	}
+}
+
+// WithTimeout sets the timeout of each request attempt, the default is 10 seconds.
+// A non-positive timeout leaves the timeout to the http client and the context passed to Fetch.
+func WithTimeout(timeout time.Duration) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.timeout = timeout
+	}
+}
+
+// WithDefaultTTL sets how long the keys are cached when the response has no caching info, the default is one hour.
+func WithDefaultTTL(ttl time.Duration) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.defaultTTL = ttl
+	}
+}
+
+// WithMaxResponseSize limits the size of a response body, the default is 1 MiB.
+// A larger body fails with a *LimitError, a non-positive size disables the limit.
+func WithMaxResponseSize(size int64) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.maxSize = size
+	}
+}
+
+// NewGoogleKeyFetcher returns an HTTPKeyFetcher which obtains the google public certificates, as DefaultKeyFetcher does.
+func NewGoogleKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(googleCertsURL, opts...)
+}
+
+// NewHTTPKeyFetcher returns an HTTPKeyFetcher which obtains a JSON Web Key Set from url, e.g. the jwks_uri of an OpenID provider.
+// Requests are retried and cached as for DefaultKeyFetcher unless configured otherwise by opts.
+func NewHTTPKeyFetcher(url string, opts ...HTTPOption) *HTTPKeyFetcher {
+	f := &HTTPKeyFetcher{
+		url:        url,
+		client:     http.DefaultClient,
+		timeout:    time.Second * 10,
+		retries:    2,
+		backoff:    time.Millisecond * 200,
+		maxBackoff: time.Second * 5,
+		defaultTTL: time.Hour,
+		maxSize:    defaultJWKSConfig.maxSize,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// URL returns the url the keys are obtained from, e.g. as the key of WithSharedCache.
+func (f *HTTPKeyFetcher) URL() string {
+	return f.url
+}
+
+// Fetch does an http request to obtain the keys, each attempt times out as set by WithTimeout.
+// returns the response body and its expiration according to the Cache-Control, Age and Expires headers.
+// If a previous response had an ETag or Last-Modified header the request is conditional,
+// a 304 response returns the previous body with ErrNotModified.
+func (f *HTTPKeyFetcher) Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	attempts := 0
+	if f.tracer != nil {
+		var span Span
+		ctx, span = f.tracer.Start(ctx, "jwt.HTTPKeyFetcher.Fetch")
+		span.SetAttribute("url", f.url)
+		defer func() {
+			span.SetAttribute("jwt.attempts", attempts)
+			span.End(err)
+		}()
+	}
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		attempts++
+		r, expires, retry, err = f.fetch(ctx)
+		if err == nil || !retry || attempt >= f.retries {
+			return r, expires, err
+		}
+		delay := f.retryDelay(attempt)
+		f.log.log(ctx, levelWarn, "jwt: retrying key fetch", "url", f.url, "attempt", attempt+1, "delay", delay, "error", err)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, time.Now(), wrapSentinel(ctx.Err(), ", retry canceled - ", err)
+		case <-t.C:
+		}
+	}
+}
+
+// retryDelay returns the jittered wait before retrying the given attempt.
+func (f *HTTPKeyFetcher) retryDelay(attempt int) time.Duration {
+	d := f.backoff
+	for i := 0; i < attempt && d < f.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > f.maxBackoff {
+		d = f.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// fetch does a single request, retry reports whether a failure is temporary.
+func (f *HTTPKeyFetcher) fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, retry bool, err error) {
+	if f.timeout > 0 {
+		var cancelFunc context.CancelFunc
+		ctx, cancelFunc = context.WithTimeout(ctx, f.timeout)
+		defer cancelFunc()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", f.url, nil)
+	if err != nil {
+		return nil, time.Now(), false, fmt.Errorf("create request - %w", err)
+	}
+	for k, v := range f.header {
+		req.Header[k] = v
+	}
+	if f.token != nil {
+		token, err := f.token(ctx)
+		if err != nil {
+			return nil, time.Now(), false, fmt.Errorf("get access token - %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	f.mu.Lock()
+	if f.etag != "" {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+	if f.lastModified != "" {
+		req.Header.Set("If-Modified-Since", f.lastModified)
+	}
+	f.mu.Unlock()
+
+	res, err := f.client.Do(req)
+
+	if err != nil {
+		return nil, time.Now(), true, fmt.Errorf("request - %w", err)
+	}
+	defer res.Body.Close()
+
+	expires = cacheExpiration(res.Header, time.Now(), f.defaultTTL)
+	if res.StatusCode == http.StatusNotModified {
+		f.mu.Lock()
+		body := f.body
+		f.mu.Unlock()
+		if body == nil {
+			return nil, time.Now(), false, fmt.Errorf("not modified response to unconditional request")
+		}
+		return io.NopCloser(bytes.NewReader(body)), expires, false, ErrNotModified
+	}
+
+	if err := checkResponse(res); err != nil {
+		io.Copy(io.Discard, io.LimitReader(res.Body, 4096)) // allow connection reuse
+		return nil, time.Now(), err.temporary(), err
+	}
+
+	body, err := readLimited(res.Body, f.maxSize)
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return nil, time.Now(), false, err
+	}
+	if err != nil {
+		return nil, time.Now(), true, fmt.Errorf("read body - %w", err)
+	}
+	f.mu.Lock()
+	f.etag = res.Header.Get("etag")
+	f.lastModified = res.Header.Get("last-modified")
+	f.body = body
+	f.mu.Unlock()
+
+	return io.NopCloser(bytes.NewReader(body)), expires, false, nil
+}
+
+// FetchError is returned by HTTPKeyFetcher when the response has a non 2xx status or a non JSON or JWT content type.
+type FetchError struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+}
+
+func (e *FetchError) Error() string {
+	if e.StatusCode < 200 || e.StatusCode > 299 {
+		return fmt.Sprintf("unexpected status %v from %v", e.StatusCode, e.URL)
+	}
+	return fmt.Sprintf("unexpected content type %q from %v", e.ContentType, e.URL)
+}
+
+// temporary reports whether the request may succeed if retried.
+func (e *FetchError) temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout
+}
+
+// checkResponse returns a *FetchError if res doesn't have a 2xx status and a JSON or JWT content type.
+func checkResponse(res *http.Response) *FetchError {
+	err := &FetchError{
+		URL:         res.Request.URL.String(),
+		StatusCode:  res.StatusCode,
+		ContentType: res.Header.Get("content-type"),
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return err
+	}
+	mediaType, _, parseErr := mime.ParseMediaType(err.ContentType)
+	if parseErr != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") && !strings.HasSuffix(mediaType, "+jwt")) {
+		return err
+	}
+	return nil
+}
+
+// errNoMaxAge is returned by extractMaxAge if the cache-control value has neither s-maxage nor max-age.
+var errNoMaxAge = errors.New("max-age not found")
+
+// extractMaxAge returns the s-maxage, or if absent the max-age value from an cache-control http response header
+// or an error if finding a max-age failed.
+func extractMaxAge(cacheCtrlValue string) (int, error) {
+	directives := cacheDirectives(cacheCtrlValue)
+	for _, name := range []string{"s-maxage", "max-age"} {
+		maxAgeStr, ok := directives[name]
+		if !ok {
+			continue
+		}
+		maxAge, err := strconv.Atoi(maxAgeStr)
+		if err != nil {
+			return 0, fmt.Errorf("convert %v value %v to number - %w", name, maxAgeStr, err)
+		}
+		if maxAge < 0 {
+			return 0, fmt.Errorf("negative %v value %v", name, maxAge)
+		}
+		return maxAge, nil
+	}
+	return 0, fmt.Errorf("%w in %v", errNoMaxAge, cacheCtrlValue)
+}
+
+// cacheDirectives returns the lower case directive names of a cache-control header value mapped to their unquoted values.
+func cacheDirectives(cacheCtrlValue string) map[string]string {
+	directives := make(map[string]string)
+	for _, v := range strings.Split(cacheCtrlValue, ",") {
+		name, value := v, ""
+		if i := strings.Index(v, "="); i >= 0 {
+			name, value = v[:i], strings.Trim(strings.TrimSpace(v[i+1:]), `"`)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			directives[name] = value
+		}
+	}
+	return directives
+}
+
+// cacheExpiration returns the expiration time of a response with header h received at now.
+// no-store and no-cache expire after minBackgroundRefresh, so that keys aren't fetched for every token,
+// otherwise s-maxage or max-age less the Age header is used, then the Expires header relative to the Date header,
+// and defaultTTL if there is no caching info.
+func cacheExpiration(h http.Header, now time.Time, defaultTTL time.Duration) time.Time {
+	cacheCtrl := h.Get("cache-control")
+	directives := cacheDirectives(cacheCtrl)
+	if _, ok := directives["no-store"]; ok {
+		return now.Add(minBackgroundRefresh)
+	}
+	if _, ok := directives["no-cache"]; ok {
+		return now.Add(minBackgroundRefresh)
+	}
+
+	maxAge, err := extractMaxAge(cacheCtrl)
+	if err == nil {
+		age, _ := strconv.Atoi(h.Get("age"))
+		if age < 0 || age > maxAge {
+			return now
+		}
+		return now.Add(time.Second * time.Duration(maxAge-age))
+	}
+	if !errors.Is(err, errNoMaxAge) {
+		return now // an invalid max-age is treated as stale
+	}
+
+	if expiresStr := h.Get("expires"); expiresStr != "" {
+		expires, err := http.ParseTime(expiresStr)
+		if err != nil {
+			return now // an invalid Expires is in the past
+		}
+		if date, err := http.ParseTime(h.Get("date")); err == nil {
+			return now.Add(expires.Sub(date))
+		}
+		return expires
+	}
+	return now.Add(defaultTTL)
+}
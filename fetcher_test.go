@@ -0,0 +1,265 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testKeyServer serves validKey after failing the first failures requests by closing the connection.
+func testKeyServer(t *testing.T, failures int32) (*httptest.Server, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("hijack - %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=100, must-revalidate")
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		io.WriteString(w, validKey)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestHTTPKeyFetcherRetry(t *testing.T) {
+	srv, requests := testKeyServer(t, 2)
+	f := NewHTTPKeyFetcher(srv.URL, WithRetry(2, time.Millisecond, time.Millisecond*5))
+
+	r, expires, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+	if until := time.Until(expires); until < time.Second*99 || until > time.Second*100 {
+		t.Errorf("expected expiration in 100s, got %v", until)
+	}
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 requests, got %v", n)
+	}
+
+	srv, requests = testKeyServer(t, 2)
+	f = NewHTTPKeyFetcher(srv.URL, WithRetry(1, time.Millisecond, time.Millisecond*5))
+	if _, _, err := f.Fetch(context.Background()); err == nil {
+		t.Errorf("exhausted retries not throwing error")
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected 2 requests, got %v", n)
+	}
+}
+
+func TestNewHTTPKeyFetcher(t *testing.T) {
+	srv, _ := testKeyServer(t, 0)
+	ver, err := NewVerifierContext(context.Background(), NewHTTPKeyFetcher(srv.URL), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	ver, err = NewVerifierContext(context.Background(), NewHTTPKeyFetcher(srv.URL), testClientID, WithIssuer("https://example.com"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err == nil {
+		t.Errorf("invalid issuer not throwing error")
+	}
+}
+
+func TestHTTPKeyFetcherClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 50)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, validKey)
+	}))
+	defer srv.Close()
+
+	// the default client doesn't trust the test server certificate
+	if _, _, err := NewHTTPKeyFetcher(srv.URL, WithRetry(0, 0, 0)).Fetch(context.Background()); err == nil {
+		t.Errorf("untrusted certificate not throwing error")
+	}
+
+	f := NewHTTPKeyFetcher(srv.URL, WithHTTPClient(srv.Client()), WithRetry(0, 0, 0))
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch with custom client failed, %v", err)
+	}
+	r.Close()
+
+	f = NewHTTPKeyFetcher(srv.URL, WithHTTPClient(srv.Client()), WithTimeout(time.Millisecond), WithRetry(0, 0, 0))
+	if _, _, err := f.Fetch(context.Background()); err == nil {
+		t.Errorf("timeout not throwing error")
+	}
+}
+
+func TestHTTPKeyFetcherAuthentication(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, validKey)
+	}))
+	defer srv.Close()
+
+	if _, _, err := NewHTTPKeyFetcher(srv.URL).Fetch(context.Background()); err == nil {
+		t.Errorf("unauthenticated request not throwing error")
+	}
+
+	token := func(ctx context.Context) (string, error) { return "token", nil }
+	f := NewHTTPKeyFetcher(srv.URL, WithHeader("X-Api-Key", "key"), WithBearerToken(token))
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("authenticated fetch failed, %v", err)
+	}
+	r.Close()
+}
+
+func TestHTTPKeyFetcherRetryCanceled(t *testing.T) {
+	srv, _ := testKeyServer(t, 10)
+	f := NewHTTPKeyFetcher(srv.URL, WithRetry(10, time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if _, _, err := f.Fetch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("canceled retry not throwing context error, got %v", err)
+	}
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	f = NewHTTPKeyFetcher(srv.URL, WithRetry(10, time.Hour, time.Hour))
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond*50, cancel)
+	_, _, err := f.Fetch(ctx)
+	var fetchErr *FetchError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected canceled error wrapping the last FetchError, got %v", err)
+	}
+}
+
+func TestHTTPKeyFetcherResponseCheck(t *testing.T) {
+	var requests int32
+	responses := []struct {
+		status      int
+		contentType string
+		retried     bool
+	}{
+		{status: 404, contentType: "application/json", retried: false},
+		{status: 503, contentType: "application/json", retried: true},
+		{status: 200, contentType: "text/html", retried: false},
+	}
+	for _, v := range responses {
+		atomic.StoreInt32(&requests, 0)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Content-Type", v.contentType)
+			w.WriteHeader(v.status)
+			io.WriteString(w, validKey)
+		}))
+		f := NewHTTPKeyFetcher(srv.URL, WithRetry(1, time.Millisecond, time.Millisecond))
+
+		_, _, err := f.Fetch(context.Background())
+		srv.Close()
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) || fetchErr.StatusCode != v.status || fetchErr.ContentType != v.contentType {
+			t.Errorf("expected FetchError for status %v and %v, got %v", v.status, v.contentType, err)
+		}
+		if retried := atomic.LoadInt32(&requests) > 1; retried != v.retried {
+			t.Errorf("status %v retried %v, expected %v", v.status, retried, v.retried)
+		}
+	}
+}
+
+func TestHTTPKeyFetcherNotModified(t *testing.T) {
+	var conditional int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=100")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, validKey)
+	}))
+	defer srv.Close()
+	f := NewHTTPKeyFetcher(srv.URL)
+
+	c, err := newKeyCache(context.Background(), f, cacheConfig{})
+	if err != nil {
+		t.Fatalf("new key cache failed, %v", err)
+	}
+	s := c.snapshot()
+	keys := fmt.Sprintf("%p", s.publicKeys)
+	c.keys.Store(&keySet{publicKeys: s.publicKeys, thumbprints: s.thumbprints, expires: time.Now().Add(-time.Second)})
+
+	k, err := c.retrieveKey(context.Background(), "f73e9e2b-242e-4842-8809-65ba74800972")
+	if err != nil || k.key == nil {
+		t.Fatalf("retrieve key failed, %v", err)
+	}
+	if atomic.LoadInt32(&conditional) != 1 {
+		t.Errorf("expected conditional request")
+	}
+	if fmt.Sprintf("%p", c.snapshot().publicKeys) != keys {
+		t.Errorf("keys rebuilt on not modified response")
+	}
+	if expires := c.snapshot().expires; expires.Before(time.Now().Add(time.Second * 99)) {
+		t.Errorf("expiration not extended, %v", expires)
+	}
+
+	// a new cache sharing the fetcher gets the keys of the previous response
+	if _, err := newKeyCache(context.Background(), f, cacheConfig{}); err != nil {
+		t.Errorf("new key cache on not modified response failed, %v", err)
+	}
+}
+
+func TestExtractMaxAgeNegative(t *testing.T) {
+	_, err := extractMaxAge("max-age=-1")
+	if err == nil || strings.Contains(err.Error(), "%!") {
+		t.Errorf("expected negative max-age error, got %v", err)
+	}
+}
+
+func TestCacheExpiration(t *testing.T) {
+	now := time.Date(2022, 3, 7, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header  http.Header
+		expires time.Time
+	}{
+		{header: http.Header{"Cache-Control": {"public,max-age=100"}}, expires: now.Add(time.Second * 100)},
+		{header: http.Header{"Cache-Control": {"Max-Age=\"100\""}}, expires: now.Add(time.Second * 100)},
+		{header: http.Header{"Cache-Control": {"max-age=100, s-maxage=200"}}, expires: now.Add(time.Second * 200)},
+		{header: http.Header{"Cache-Control": {"max-age=100"}, "Age": {"40"}}, expires: now.Add(time.Second * 60)},
+		{header: http.Header{"Cache-Control": {"max-age=100"}, "Age": {"400"}}, expires: now},
+		{header: http.Header{"Cache-Control": {"max-age=100, no-cache"}}, expires: now.Add(minBackgroundRefresh)},
+		{header: http.Header{"Cache-Control": {"no-store"}}, expires: now.Add(minBackgroundRefresh)},
+		{header: http.Header{"Cache-Control": {"max-age=abc"}}, expires: now},
+		{header: http.Header{"Cache-Control": {"max-age=-1"}}, expires: now},
+		{header: http.Header{"Expires": {"Mon, 07 Mar 2022 13:00:00 GMT"}, "Date": {"Mon, 07 Mar 2022 11:00:00 GMT"}}, expires: now.Add(time.Hour * 2)},
+		{header: http.Header{"Expires": {"Mon, 07 Mar 2022 13:00:00 GMT"}}, expires: now.Add(time.Hour)},
+		{header: http.Header{"Expires": {"0"}}, expires: now},
+		{header: http.Header{}, expires: now.Add(time.Minute)},
+	}
+	for _, v := range tests {
+		if expires := cacheExpiration(v.header, now, time.Minute); !expires.Equal(v.expires) {
+			t.Errorf("expected %v for %v, got %v", v.expires, v.header, expires)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileKeyFetcher is a KeyFetcher which reads a JSON Web Key Set from a file.
+// The file is read again only when its modification time or size changes,
+// so that key sets mounted from e.g. a Kubernetes secret can be rotated without a restart.
+type FileKeyFetcher struct {
+	fsys         fs.FS
+	name         string
+	pollInterval time.Duration
+
+	// mu guards the last read file
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	body    []byte
+}
+
+// NewFileKeyFetcher returns a FileKeyFetcher for the file at path, the keys expire pollInterval after every Fetch.
+func NewFileKeyFetcher(path string, pollInterval time.Duration) *FileKeyFetcher {
+	return NewFSKeyFetcher(os.DirFS(filepath.Dir(path)), filepath.Base(path), pollInterval)
+}
+
+// NewFSKeyFetcher is like NewFileKeyFetcher for the file name in fsys, e.g. an embed.FS.
+func NewFSKeyFetcher(fsys fs.FS, name string, pollInterval time.Duration) *FileKeyFetcher {
+	return &FileKeyFetcher{
+		fsys:         fsys,
+		name:         name,
+		pollInterval: pollInterval,
+	}
+}
+
+// Fetch returns the file contents, or ErrNotModified if the file didn't change since the previous Fetch.
+func (f *FileKeyFetcher) Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	expires = time.Now().Add(f.pollInterval)
+	info, err := fs.Stat(f.fsys, f.name)
+	if err != nil {
+		return nil, time.Now(), fmt.Errorf("stat key file - %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.body != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return io.NopCloser(bytes.NewReader(f.body)), expires, ErrNotModified
+	}
+
+	body, err := fs.ReadFile(f.fsys, f.name)
+	if err != nil {
+		return nil, time.Now(), fmt.Errorf("read key file - %w", err)
+	}
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	f.body = body
+	return io.NopCloser(bytes.NewReader(body)), expires, nil
+}
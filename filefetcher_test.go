@@ -0,0 +1,61 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileKeyFetcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	rotatedKey := strings.Replace(validKey, "f73e9e2b-242e-4842-8809-65ba74800972", "old-key", 1)
+	if err := os.WriteFile(path, []byte(rotatedKey), 0600); err != nil {
+		t.Fatalf("write key file failed, %v", err)
+	}
+
+	ver, err := NewVerifierContext(context.Background(), NewFileKeyFetcher(path, 0), testClientID, WithUnknownKeyRefresh(0))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err == nil {
+		t.Errorf("token with unknown key not throwing error")
+	}
+
+	if err := os.WriteFile(path, []byte(validKey), 0600); err != nil {
+		t.Fatalf("write key file failed, %v", err)
+	}
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("change key file time failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail after key file rotation, %v", err)
+	}
+}
+
+func TestFSKeyFetcherNotModified(t *testing.T) {
+	fsys := fstest.MapFS{"jwks.json": &fstest.MapFile{Data: []byte(validKey), ModTime: time.Now()}}
+	f := NewFSKeyFetcher(fsys, "jwks.json", time.Minute)
+
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+
+	r, expires, err := f.Fetch(context.Background())
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("expected ErrNotModified, got %v", err)
+	}
+	if b, _ := io.ReadAll(r); string(b) != validKey {
+		t.Errorf("not modified fetch returned %q", b)
+	}
+	if time.Until(expires) < time.Second*59 {
+		t.Errorf("expected expiration in a minute, got %v", expires)
+	}
+}
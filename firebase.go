@@ -0,0 +1,40 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NewFirebaseVerifier returns a Verifier which parses and verifies Firebase Authentication ID tokens of projectID.
+// Tokens are verified with the keys of NewFirebaseKeyFetcher, their issuer must be https://securetoken.google.com/<projectID>,
+// their audience projectID, their subject, the user's uid, non-empty and their auth_time in the past.
+func NewFirebaseVerifier(projectID string, opts ...Option) (*Verifier, error) {
+	return newFirebaseVerifier(context.Background(), NewFirebaseKeyFetcher(), projectID, opts)
+}
+
+func newFirebaseVerifier(ctx context.Context, keyFetcher KeyFetcher, projectID string, opts []Option) (*Verifier, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("empty Firebase project ID")
+	}
+	opts = append([]Option{WithKeyFormat(FormatX509), withFirebaseChecks}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "https://securetoken.google.com/"+projectID, projectID, opts)
+}
+
+// withFirebaseChecks adds checkFirebaseClaims at the time of the clock of the Verifier.
+func withFirebaseChecks(v *Verifier) {
+	v.checks = append(v.checks, func(token *JWT) error {
+		return checkFirebaseClaims(token, v.now())
+	})
+}
+
+// checkFirebaseClaims checks the claims required by Firebase Authentication besides those checked by every Verifier.
+func checkFirebaseClaims(token *JWT, now time.Time) error {
+	if token.Claims.SUB == "" {
+		return fmt.Errorf("empty subject")
+	}
+	if token.Claims.AuthTime > now.Unix() {
+		return fmt.Errorf("token authenticated for future time")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFirebaseVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	cert := testCert(t, key, nil, nil)
+	certs, err := json.Marshal(map[string]string{
+		"test": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+	})
+	if err != nil {
+		t.Fatalf("marshal certificates failed, %v", err)
+	}
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		return io.NopCloser(strings.NewReader(string(certs))), time.Now().Add(time.Hour), nil
+	}
+
+	ver, err := newFirebaseVerifier(context.Background(), fetcher, "my-project", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	valid := map[string]interface{}{
+		"iss":       "https://securetoken.google.com/my-project",
+		"aud":       "my-project",
+		"sub":       "uid",
+		"auth_time": time.Now().Add(-time.Minute).Unix(),
+	}
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"valid", nil, true},
+		{"google issuer", map[string]interface{}{"iss": "https://accounts.google.com"}, false},
+		{"other project", map[string]interface{}{"aud": "other-project"}, false},
+		{"empty uid", map[string]interface{}{"sub": ""}, false},
+		{"future auth_time", map[string]interface{}{"auth_time": time.Now().Add(time.Hour).Unix()}, false},
+	}
+	for _, test := range tests {
+		claims := make(map[string]interface{})
+		for k, v := range valid {
+			claims[k] = v
+		}
+		for k, v := range test.claims {
+			claims[k] = v
+		}
+		_, err := ver.ParseAndVerify(testToken(t, key, nil, claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	future := time.Now().Add(time.Hour)
+	ver, err = newFirebaseVerifier(context.Background(), fetcher, "my-project", []Option{WithClock(func() time.Time { return future.Add(time.Minute) })})
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	valid["auth_time"], valid["exp"] = future.Unix(), future.Add(time.Hour).Unix()
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, valid)); err != nil {
+		t.Errorf("auth_time not checked with clock, %v", err)
+	}
+
+	if _, err := NewFirebaseVerifier(""); err == nil {
+		t.Errorf("empty project ID not throwing error")
+	}
+}
@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+)
+
+// githubIssuer is the issuer of GitHub Actions OIDC tokens.
+const githubIssuer = "https://token.actions.githubusercontent.com"
+
+// GitHubClaims are the GitHub Actions specific claims of an OIDC token, decoded with JWT.UnmarshalClaims.
+type GitHubClaims struct {
+	Repository           string `json:"repository"` // owner/name
+	RepositoryID         string `json:"repository_id"`
+	RepositoryOwner      string `json:"repository_owner"`
+	RepositoryOwnerID    string `json:"repository_owner_id"`
+	RepositoryVisibility string `json:"repository_visibility"`
+	Ref                  string `json:"ref"` // e.g. refs/heads/main
+	RefType              string `json:"ref_type"`
+	SHA                  string `json:"sha"`
+	HeadRef              string `json:"head_ref"`
+	BaseRef              string `json:"base_ref"`
+	Environment          string `json:"environment"`
+	EventName            string `json:"event_name"`
+	Workflow             string `json:"workflow"`
+	WorkflowRef          string `json:"workflow_ref"`
+	JobWorkflowRef       string `json:"job_workflow_ref"`
+	Actor                string `json:"actor"`
+	ActorID              string `json:"actor_id"`
+	RunID                string `json:"run_id"`
+	RunNumber            string `json:"run_number"`
+	RunAttempt           string `json:"run_attempt"`
+	RunnerEnvironment    string `json:"runner_environment"`
+}
+
+// NewGitHubKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign GitHub Actions OIDC tokens.
+func NewGitHubKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(githubIssuer+"/.well-known/jwks", opts...)
+}
+
+// NewGitHubVerifier returns a Verifier which parses and verifies GitHub Actions OIDC tokens issued to audience,
+// by default the URL of the repository owner, e.g. https://github.com/octo-org.
+// Tokens are verified with the keys of NewGitHubKeyFetcher and their issuer must be https://token.actions.githubusercontent.com.
+// Workflows are restricted with WithGitHubRepositories, WithGitHubRefs and WithGitHubEnvironments.
+func NewGitHubVerifier(audience string, opts ...Option) (*Verifier, error) {
+	return newFetchingVerifier(context.Background(), NewGitHubKeyFetcher(), githubIssuer, audience, opts)
+}
+
+// WithGitHubRepositories only accepts tokens of a repository, as owner/name, matching one of the path.Match patterns,
+// e.g. octo-org/octo-repo or octo-org/*.
+func WithGitHubRepositories(patterns ...string) Option {
+	return withGitHubCheck("repository", func(c GitHubClaims) string { return c.Repository }, patterns)
+}
+
+// WithGitHubRefs only accepts tokens of a git ref matching one of the path.Match patterns,
+// e.g. refs/heads/main or refs/tags/v*.
+func WithGitHubRefs(patterns ...string) Option {
+	return withGitHubCheck("ref", func(c GitHubClaims) string { return c.Ref }, patterns)
+}
+
+// WithGitHubEnvironments only accepts tokens of a job deploying to an environment matching one of the path.Match patterns.
+func WithGitHubEnvironments(patterns ...string) Option {
+	return withGitHubCheck("environment", func(c GitHubClaims) string { return c.Environment }, patterns)
+}
+
+func withGitHubCheck(name string, claim func(GitHubClaims) string, patterns []string) Option {
+	return withChecks(func(token *JWT) error {
+		var claims GitHubClaims
+		if err := token.UnmarshalClaims(&claims); err != nil {
+			return fmt.Errorf("unable to json decode claims, %w", err)
+		}
+		return matchClaim(name, claim(claims), patterns)
+	})
+}
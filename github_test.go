@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGitHubVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	claims := func(repo, ref, env string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss": githubIssuer, "aud": "https://github.com/octo-org",
+			"repository": repo, "ref": ref, "environment": env,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"no restrictions", nil, claims("octo-org/octo-repo", "refs/heads/main", ""), true},
+		{"repository", []Option{WithGitHubRepositories("octo-org/octo-repo")}, claims("octo-org/octo-repo", "refs/heads/main", ""), true},
+		{"repository pattern", []Option{WithGitHubRepositories("octo-org/*")}, claims("octo-org/octo-repo", "refs/heads/main", ""), true},
+		{"other repository", []Option{WithGitHubRepositories("octo-org/*")}, claims("evil-org/octo-repo", "refs/heads/main", ""), false},
+		{"tag ref", []Option{WithGitHubRefs("refs/tags/v*")}, claims("octo-org/octo-repo", "refs/tags/v1.0", ""), true},
+		{"branch ref", []Option{WithGitHubRefs("refs/tags/v*")}, claims("octo-org/octo-repo", "refs/heads/main", ""), false},
+		{"environment", []Option{WithGitHubEnvironments("production")}, claims("octo-org/octo-repo", "refs/heads/main", "production"), true},
+		{"no environment", []Option{WithGitHubEnvironments("*")}, claims("octo-org/octo-repo", "refs/heads/main", ""), false},
+	}
+	for _, test := range tests {
+		ver, err := newFetchingVerifier(context.Background(), keyGetterFunc(jwks), githubIssuer, "https://github.com/octo-org", test.opts)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		token, err := ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+		if err == nil {
+			var c GitHubClaims
+			if err := token.UnmarshalClaims(&c); err != nil || c.Repository != test.claims["repository"] {
+				t.Errorf("%v: unexpected claims %+v, %v", test.name, c, err)
+			}
+		}
+	}
+}
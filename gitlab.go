@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GitLabClaims are the GitLab CI/CD specific claims of an ID token, decoded with JWT.UnmarshalClaims.
+type GitLabClaims struct {
+	NamespaceID          string `json:"namespace_id"`
+	NamespacePath        string `json:"namespace_path"`
+	ProjectID            string `json:"project_id"`
+	ProjectPath          string `json:"project_path"` // group/project
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserEmail            string `json:"user_email"`
+	PipelineID           string `json:"pipeline_id"`
+	PipelineSource       string `json:"pipeline_source"` // e.g. push, merge_request_event or schedule
+	JobID                string `json:"job_id"`
+	Ref                  string `json:"ref"` // the branch or tag name
+	RefType              string `json:"ref_type"`
+	RefPath              string `json:"ref_path"` // e.g. refs/heads/main
+	RefProtected         Bool   `json:"ref_protected"`
+	Environment          string `json:"environment"`
+	EnvironmentProtected Bool   `json:"environment_protected"`
+	DeploymentTier       string `json:"deployment_tier"`
+	RunnerID             int64  `json:"runner_id"`
+	RunnerEnvironment    string `json:"runner_environment"`
+	SHA                  string `json:"sha"`
+	CIConfigRefURI       string `json:"ci_config_ref_uri"`
+}
+
+// NewGitLabKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign the CI/CD ID tokens of
+// the GitLab instance at baseURL, e.g. https://gitlab.com.
+func NewGitLabKeyFetcher(baseURL string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(strings.TrimSuffix(baseURL, "/")+"/oauth/discovery/keys", opts...)
+}
+
+// NewGitLabVerifier returns a Verifier which parses and verifies the CI/CD ID tokens of the GitLab instance at baseURL,
+// e.g. https://gitlab.com, issued to audience, the aud of the id_tokens keyword.
+// Tokens are verified with the keys of NewGitLabKeyFetcher and their issuer must be baseURL.
+// Jobs are restricted with WithGitLabProjects, WithGitLabRefs, WithGitLabPipelineSources and WithGitLabProtectedRef.
+func NewGitLabVerifier(baseURL, audience string, opts ...Option) (*Verifier, error) {
+	return newGitLabVerifier(context.Background(), NewGitLabKeyFetcher(baseURL), baseURL, audience, opts)
+}
+
+func newGitLabVerifier(ctx context.Context, keyFetcher KeyFetcher, baseURL, audience string, opts []Option) (*Verifier, error) {
+	return newFetchingVerifier(ctx, keyFetcher, strings.TrimSuffix(baseURL, "/"), audience, opts)
+}
+
+// WithGitLabProjects only accepts tokens of a project, as group/project, matching one of the path.Match patterns,
+// e.g. my-group/my-project or my-group/*.
+func WithGitLabProjects(patterns ...string) Option {
+	return withGitLabCheck(func(c GitLabClaims) error { return matchClaim("project_path", c.ProjectPath, patterns) })
+}
+
+// WithGitLabRefs only accepts tokens of a branch or tag name matching one of the path.Match patterns, e.g. main or release-*.
+func WithGitLabRefs(patterns ...string) Option {
+	return withGitLabCheck(func(c GitLabClaims) error { return matchClaim("ref", c.Ref, patterns) })
+}
+
+// WithGitLabPipelineSources only accepts tokens of pipelines triggered by one of sources, e.g. push or schedule.
+func WithGitLabPipelineSources(sources ...string) Option {
+	return withGitLabCheck(func(c GitLabClaims) error {
+		for _, source := range sources {
+			if source == c.PipelineSource {
+				return nil
+			}
+		}
+		return fmt.Errorf("pipeline_source %q not allowed", c.PipelineSource)
+	})
+}
+
+// WithGitLabProtectedRef only accepts tokens of jobs running for a protected branch or tag.
+func WithGitLabProtectedRef() Option {
+	return withGitLabCheck(func(c GitLabClaims) error {
+		if !c.RefProtected {
+			return fmt.Errorf("ref %q not protected", c.Ref)
+		}
+		return nil
+	})
+}
+
+func withGitLabCheck(check func(GitLabClaims) error) Option {
+	return withChecks(func(token *JWT) error {
+		var claims GitLabClaims
+		if err := token.UnmarshalClaims(&claims); err != nil {
+			return fmt.Errorf("unable to json decode claims, %w", err)
+		}
+		return check(claims)
+	})
+}
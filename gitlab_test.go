@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGitLabVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	claims := func(project, ref, source, protected string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss": "https://gitlab.example.com", "aud": "https://vault.example.com",
+			"project_path": project, "ref": ref, "pipeline_source": source, "ref_protected": protected,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"no restrictions", nil, claims("my-group/my-project", "main", "push", "false"), true},
+		{"project pattern", []Option{WithGitLabProjects("my-group/*")}, claims("my-group/my-project", "main", "push", "false"), true},
+		{"other project", []Option{WithGitLabProjects("my-group/*")}, claims("other/my-project", "main", "push", "false"), false},
+		{"ref", []Option{WithGitLabRefs("main", "release-*")}, claims("my-group/my-project", "release-1", "push", "false"), true},
+		{"other ref", []Option{WithGitLabRefs("main")}, claims("my-group/my-project", "feature", "push", "false"), false},
+		{"pipeline source", []Option{WithGitLabPipelineSources("push")}, claims("my-group/my-project", "main", "push", "false"), true},
+		{"other pipeline source", []Option{WithGitLabPipelineSources("push")}, claims("my-group/my-project", "main", "merge_request_event", "false"), false},
+		{"protected ref", []Option{WithGitLabProtectedRef()}, claims("my-group/my-project", "main", "push", "true"), true},
+		{"unprotected ref", []Option{WithGitLabProtectedRef()}, claims("my-group/my-project", "main", "push", "false"), false},
+		{"other instance", nil, map[string]interface{}{"iss": "https://gitlab.com", "aud": "https://vault.example.com"}, false},
+	}
+	for _, test := range tests {
+		ver, err := newGitLabVerifier(context.Background(), keyGetterFunc(jwks), "https://gitlab.example.com/", "https://vault.example.com", test.opts)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		_, err = ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithHealthGracePeriod makes Healthy tolerate failed key refreshes for grace after the cached keys expired,
+// so that a readiness probe doesn't flap on a short outage of the key endpoint. The default is no grace period.
+func WithHealthGracePeriod(grace time.Duration) Option {
+	return func(v *Verifier) {
+		v.healthGrace = grace
+	}
+}
+
+// Healthy returns nil if the Verifier has cached keys which are not expired beyond the grace period of
+// WithHealthGracePeriod, e.g. for the readiness probe of a service which can't serve requests without verifying tokens.
+// Expired keys are refreshed with ctx first, a failure is returned as a *KeyFetchError.
+// A Verifier with static keys is always healthy.
+func (v *Verifier) Healthy(ctx context.Context) error {
+	return v.keys.healthy(ctx, v.healthGrace)
+}
+
+// healthy refreshes the keys if they are expired and returns an error if there are none or they expired more than grace ago.
+func (v *keyCache) healthy(ctx context.Context, grace time.Duration) error {
+	if v.keyFetcher == nil {
+		return nil
+	}
+	s := v.snapshot()
+	if s.publicKeys != nil && time.Now().Before(s.expires) {
+		return nil
+	}
+
+	err := v.refreshExpired(ctx)
+	if v.snapshot().publicKeys == nil {
+		if err == nil {
+			err = errors.New("no keys")
+		}
+		return &KeyFetchError{Err: fmt.Errorf("no cached keys - %w", err)}
+	}
+	if err == nil || time.Since(s.expires) < grace {
+		return nil
+	}
+	return &KeyFetchError{Err: fmt.Errorf("keys expired %v ago - %w", time.Since(s.expires).Round(time.Second), err)}
+}
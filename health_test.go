@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifierHealthy(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	var fetchErr error
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		if fetchErr != nil {
+			return nil, time.Time{}, fetchErr
+		}
+		return io.NopCloser(strings.NewReader(jwks)), time.Now().Add(-time.Second), nil
+	}
+	ctx := context.Background()
+
+	fetchErr = errors.New("unavailable")
+	ver, err := NewVerifier(fetcher, testClientID)
+	if err == nil {
+		t.Fatalf("failed fetch not throwing error")
+	}
+	var fe *KeyFetchError
+	if err := ver.Healthy(ctx); !errors.As(err, &fe) {
+		t.Errorf("verifier without keys healthy, %v", err)
+	}
+
+	fetchErr = nil
+	if err := ver.Healthy(ctx); err != nil {
+		t.Errorf("refreshed verifier unhealthy, %v", err)
+	}
+
+	fetchErr = errors.New("unavailable")
+	if err := ver.Healthy(ctx); !errors.Is(err, fetchErr) {
+		t.Errorf("verifier with expired keys healthy, %v", err)
+	}
+	WithHealthGracePeriod(time.Minute)(ver)
+	if err := ver.Healthy(ctx); err != nil {
+		t.Errorf("verifier with expired keys within grace unhealthy, %v", err)
+	}
+
+	static, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"test": key.Public()}, testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if err := static.Healthy(ctx); err != nil {
+		t.Errorf("static verifier unhealthy, %v", err)
+	}
+}
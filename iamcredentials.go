@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// iamCredentialsURL is the service accounts collection of the IAM Credentials API, a variable for tests.
+var iamCredentialsURL = "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/"
+
+// NewIAMIDTokenFunc returns an AccessTokenFunc which returns Google issued ID tokens of the service account email for audience,
+// generated by the generateIdToken method of the IAM Credentials API. It needs no key file, e.g. with workload identity.
+// Requests are sent with client, or http.DefaultClient if nil, and authorized with the access token returned by token,
+// which may be nil if client authorizes requests. The caller needs the Service Account OpenID Connect Identity Token Creator role on email.
+// A token is reused until 5 minutes before its expiration.
+// The module github.com/meblum/jwt/jwtoauth2 exposes the tokens as a golang.org/x/oauth2 TokenSource.
+func NewIAMIDTokenFunc(client *http.Client, token AccessTokenFunc, email, audience string) (AccessTokenFunc, error) {
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid service account email %v", email)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(struct {
+		Audience     string `json:"audience"`
+		IncludeEmail bool   `json:"includeEmail"`
+	}{audience, true})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request - %w", err)
+	}
+
+	var mu sync.Mutex
+	var idToken string
+	var expires time.Time
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if idToken != "" && time.Now().Add(serviceAccountTokenRefresh).Before(expires) {
+			return idToken, nil
+		}
+		t, err := generateIDToken(ctx, client, token, iamCredentialsURL+url.PathEscape(email)+":generateIdToken", body)
+		if err != nil {
+			return "", fmt.Errorf("generate ID token - %w", err)
+		}
+		parsed, _, err := splitJWT(t, false, true, nil)
+		if err != nil {
+			return "", err
+		}
+		idToken, expires = t, time.Unix(parsed.Claims.EXP, 0)
+		return idToken, nil
+	}, nil
+}
+
+// generateIDToken sends the generateIdToken request body to u and returns the token of the response.
+func generateIDToken(ctx context.Context, client *http.Client, token AccessTokenFunc, u string, body []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request - %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	if token != nil {
+		accessToken, err := token(ctx)
+		if err != nil {
+			return "", fmt.Errorf("get access token - %w", err)
+		}
+		req.Header.Set("authorization", "Bearer "+accessToken)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request - %w", err)
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		io.Copy(io.Discard, io.LimitReader(res.Body, 4096)) // allow connection reuse
+		return "", err
+	}
+	var r struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&r); err != nil {
+		return "", fmt.Errorf("decode response - %w", err)
+	}
+	return r.Token, nil
+}
@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIAMIDTokenFunc(t *testing.T) {
+	key, _ := testEd25519Key(t)
+	const email = "invoker@my-project.iam.gserviceaccount.com"
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/"+email+":generateIdToken" || r.Header.Get("authorization") != "Bearer access" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		var req struct {
+			Audience string `json:"audience"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request failed, %v", err)
+		}
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"token": testToken(t, key, nil, map[string]interface{}{
+			"iss": "https://accounts.google.com", "aud": req.Audience, "email": email, "exp": time.Now().Add(time.Hour).Unix(),
+		})})
+	}))
+	defer srv.Close()
+	defer func(u string) { iamCredentialsURL = u }(iamCredentialsURL)
+	iamCredentialsURL = srv.URL + "/"
+
+	access := func(context.Context) (string, error) { return "access", nil }
+	tokenFunc, err := NewIAMIDTokenFunc(nil, access, email, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("New ID token func failed, %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := tokenFunc(context.Background()); err != nil {
+			t.Fatalf("generate token failed, %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %v", requests)
+	}
+
+	tokenFunc, err = NewIAMIDTokenFunc(nil, func(context.Context) (string, error) { return "other", nil }, email, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("New ID token func failed, %v", err)
+	}
+	var fetchErr *FetchError
+	if _, err := tokenFunc(context.Background()); !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusForbidden {
+		t.Errorf("expected forbidden FetchError, got %v", err)
+	}
+	if _, err := NewIAMIDTokenFunc(nil, access, "invoker", "https://api.example.com"); err == nil {
+		t.Errorf("invalid email not throwing error")
+	}
+}
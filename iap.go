@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// iapKeysURL publishes the JSON Web Key Set of the keys which sign Identity-Aware Proxy assertions.
+const iapKeysURL = "https://www.gstatic.com/iap/verify/public_key-jwk"
+
+// IAPHeader is the request header which holds the signed assertion added by Identity-Aware Proxy.
+const IAPHeader = "X-Goog-IAP-JWT-Assertion"
+
+// NewIAPKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign Identity-Aware Proxy assertions.
+func NewIAPKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(iapKeysURL, opts...)
+}
+
+// NewIAPVerifier returns a Verifier which parses and verifies Identity-Aware Proxy assertions, signed with ES256.
+// Assertions are verified with the keys of NewIAPKeyFetcher, their issuer must be https://cloud.google.com/iap
+// and their audience audience, as returned by IAPAppEngineAudience or IAPBackendServiceAudience.
+func NewIAPVerifier(audience string, opts ...Option) (*Verifier, error) {
+	return newIAPVerifier(context.Background(), NewIAPKeyFetcher(), audience, opts)
+}
+
+func newIAPVerifier(ctx context.Context, keyFetcher KeyFetcher, audience string, opts []Option) (*Verifier, error) {
+	if audience == "" {
+		return nil, fmt.Errorf("empty IAP audience")
+	}
+	opts = append([]Option{withChecks(checkIAPClaims)}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "https://cloud.google.com/iap", audience, opts)
+}
+
+// checkIAPClaims checks that an assertion is signed with ES256, as all IAP assertions are.
+func checkIAPClaims(token *JWT) error {
+	if token.Header.ALG != "ES256" {
+		return fmt.Errorf("expected alg ES256, but token alg is %v", token.Header.ALG)
+	}
+	return nil
+}
+
+// IAPAppEngineAudience returns the IAP audience of the App Engine app of a project.
+func IAPAppEngineAudience(projectNumber, projectID string) string {
+	return fmt.Sprintf("/projects/%v/apps/%v", projectNumber, projectID)
+}
+
+// IAPBackendServiceAudience returns the IAP audience of a Compute Engine or GKE backend service of a project.
+func IAPBackendServiceAudience(projectNumber, backendServiceID string) string {
+	return fmt.Sprintf("/projects/%v/global/backendServices/%v", projectNumber, backendServiceID)
+}
+
+// IAPAssertion returns the IAP assertion of r, to be verified with a Verifier returned by NewIAPVerifier.
+func IAPAssertion(r *http.Request) (string, error) {
+	return HeaderExtractor(IAPHeader).Extract(r)
+}
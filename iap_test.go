@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIAPVerifier(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	x := base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32)))
+	y := base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32)))
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"EC","alg":"ES256","use":"sig","kid":"test","crv":"P-256","x":"%v","y":"%v"}]}`, x, y)
+
+	audience := IAPAppEngineAudience("1234", "my-project")
+	ver, err := newIAPVerifier(context.Background(), keyGetterFunc(jwks), audience, nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	token := testToken(t, key, nil, map[string]interface{}{"iss": "https://cloud.google.com/iap", "aud": audience})
+	req := httptest.NewRequest("GET", "/", nil)
+	if _, err := IAPAssertion(req); err == nil {
+		t.Errorf("missing assertion not throwing error")
+	}
+	req.Header.Set("x-goog-iap-jwt-assertion", token)
+	assertion, err := IAPAssertion(req)
+	if err != nil {
+		t.Fatalf("get assertion failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(assertion); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	otherAudience := testToken(t, key, nil, map[string]interface{}{"iss": "https://cloud.google.com/iap", "aud": IAPBackendServiceAudience("1234", "5678")})
+	if _, err := ver.ParseAndVerify(otherAudience); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+
+	forged := []byte(token)
+	forged[len(forged)-2] ^= 1
+	if _, err := ver.ParseAndVerify(string(forged)); err == nil {
+		t.Errorf("invalid signature not throwing error")
+	}
+}
@@ -0,0 +1,127 @@
+// Package idtoken validates Google issued ID tokens with the API of google.golang.org/api/idtoken,
+// backed by the verifiers of github.com/meblum/jwt.
+package idtoken
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meblum/jwt"
+)
+
+// Payload is the claims of an ID token, as the Payload of google.golang.org/api/idtoken.
+type Payload struct {
+	Issuer   string                 `json:"iss"`
+	Audience string                 `json:"aud"`
+	Expires  int64                  `json:"exp"`
+	IssuedAt int64                  `json:"iat"`
+	Subject  string                 `json:"sub"`
+	Claims   map[string]interface{} `json:"-"`
+}
+
+// The key fetchers of Google ID tokens and IAP assertions, variables for tests.
+var (
+	googleKeyFetcher jwt.KeyFetcher = jwt.NewGoogleKeyFetcher()
+	iapKeyFetcher    jwt.KeyFetcher = jwt.NewIAPKeyFetcher()
+)
+
+// maxAudiences is the maximal number of audiences whose verifiers are kept.
+const maxAudiences = 64
+
+// verifiers maps audiences to the verifiers of their tokens, which share the key caches of all audiences.
+// The verifiers of the maxAudiences most recently used audiences are kept.
+var verifiers = struct {
+	mu   sync.Mutex
+	sets map[string]*audienceSet
+}{sets: make(map[string]*audienceSet)}
+
+// audienceSet is the verifiers of an audience and the time they were last used.
+type audienceSet struct {
+	set  *jwt.VerifierSet
+	used time.Time
+}
+
+// Validate verifies an ID token issued by Google to audience, signed with RS256, or an IAP assertion signed with ES256,
+// and returns its payload. Google ID tokens may have the issuer https://accounts.google.com or accounts.google.com.
+func Validate(ctx context.Context, idToken, audience string) (*Payload, error) {
+	set, err := verifierSet(ctx, audience)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := set.ParseAndVerifyContext(ctx, idToken); err != nil {
+		return nil, fmt.Errorf("idtoken: %w", err)
+	}
+	return ParsePayload(idToken)
+}
+
+// verifierSet returns the verifiers of audience, creating them if they don't exist
+// and closing those of the least recently used audience if there are maxAudiences.
+func verifierSet(ctx context.Context, audience string) (*jwt.VerifierSet, error) {
+	verifiers.mu.Lock()
+	defer verifiers.mu.Unlock()
+	if s := verifiers.sets[audience]; s != nil {
+		s.used = time.Now()
+		return s.set, nil
+	}
+
+	googleURL, iapURL := jwt.NewGoogleKeyFetcher().URL(), jwt.NewIAPKeyFetcher().URL()
+	set := jwt.NewVerifierSet()
+	for _, issuer := range []string{"https://accounts.google.com", "accounts.google.com"} {
+		v, err := jwt.NewVerifierContext(ctx, googleKeyFetcher, audience, jwt.WithIssuer(issuer), jwt.WithSharedCache(googleURL))
+		if err != nil {
+			set.Close()
+			return nil, fmt.Errorf("idtoken: %w", err)
+		}
+		set.Add(v)
+	}
+	v, err := jwt.NewVerifierContext(ctx, iapKeyFetcher, audience, jwt.WithIssuer("https://cloud.google.com/iap"), jwt.WithSharedCache(iapURL))
+	if err != nil {
+		set.Close()
+		return nil, fmt.Errorf("idtoken: %w", err)
+	}
+	set.Add(v)
+	if len(verifiers.sets) >= maxAudiences {
+		var oldest string
+		for aud, s := range verifiers.sets {
+			if oldest == "" || s.used.Before(verifiers.sets[oldest].used) {
+				oldest = aud
+			}
+		}
+		verifiers.sets[oldest].set.Close()
+		delete(verifiers.sets, oldest)
+	}
+	verifiers.sets[audience] = &audienceSet{set: set, used: time.Now()}
+	return set, nil
+}
+
+// ParsePayload returns the payload of idToken without verifying it.
+func ParsePayload(idToken string) (*Payload, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("idtoken: invalid token, token must have three segments; found %d", len(parts))
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("idtoken: unable to decode JWT claims: %w", err)
+	}
+	var claims struct {
+		Payload
+		AUD jwt.Audience `json:"aud"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, fmt.Errorf("idtoken: unable to unmarshal JWT payload: %w", err)
+	}
+	p := claims.Payload
+	if len(claims.AUD) > 0 {
+		p.Audience = claims.AUD[0]
+	}
+	if err := json.Unmarshal(b, &p.Claims); err != nil {
+		return nil, fmt.Errorf("idtoken: unable to unmarshal JWT payload claims: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,99 @@
+package idtoken
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meblum/jwt"
+)
+
+func testToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	h, err := json.Marshal(map[string]interface{}{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	if err != nil {
+		t.Fatalf("marshal header failed, %v", err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims failed, %v", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	hashed := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("sign failed, %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestValidate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"RSA","kid":"test","e":"AQAB","n":"%v"}]}`, base64.RawURLEncoding.EncodeToString(key.N.Bytes()))
+	var fetcher jwt.KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		return io.NopCloser(strings.NewReader(jwks)), time.Now().Add(time.Hour), nil
+	}
+	googleKeyFetcher, iapKeyFetcher = fetcher, fetcher
+
+	claims := func(iss, aud string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss": iss, "aud": aud, "sub": "1234", "email": "1234@gmail.com",
+			"iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"https issuer", testToken(t, key, claims("https://accounts.google.com", "aud")), true},
+		{"bare issuer", testToken(t, key, claims("accounts.google.com", "aud")), true},
+		{"other issuer", testToken(t, key, claims("https://evil.example.com", "aud")), false},
+		{"other audience", testToken(t, key, claims("https://accounts.google.com", "other")), false},
+	}
+	for _, test := range tests {
+		p, err := Validate(context.Background(), test.token, "aud")
+		if test.valid && err != nil {
+			t.Errorf("%v: validate fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+		if err == nil && (p.Audience != "aud" || p.Subject != "1234" || p.Claims["email"] != "1234@gmail.com") {
+			t.Errorf("%v: unexpected payload %+v", test.name, p)
+		}
+	}
+
+	if _, err := ParsePayload("invalid"); err == nil {
+		t.Errorf("invalid token not throwing error")
+	}
+
+	for i := 0; i < maxAudiences*2; i++ {
+		aud := fmt.Sprintf("aud-%v", i)
+		if _, err := Validate(context.Background(), testToken(t, key, claims("https://accounts.google.com", aud)), aud); err != nil {
+			t.Fatalf("validate %v fail, %v", aud, err)
+		}
+	}
+	verifiers.mu.Lock()
+	n := len(verifiers.sets)
+	verifiers.mu.Unlock()
+	if n > maxAudiences {
+		t.Errorf("expected at most %v audiences, got %v", maxAudiences, n)
+	}
+	// the verifiers of an evicted audience are created again
+	if _, err := Validate(context.Background(), testToken(t, key, claims("https://accounts.google.com", "aud-0")), "aud-0"); err != nil {
+		t.Errorf("validate evicted audience fail, %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Introspector is an OAuth 2.0 token introspection (RFC 7662) client, for opaque tokens or to check that a token isn't revoked.
+type Introspector struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewIntrospector returns an Introspector of the introspection endpoint of an authorization server,
+// authenticating as clientID with clientSecret using HTTP basic authentication.
+// A nil client uses http.DefaultClient.
+func NewIntrospector(endpoint, clientID, clientSecret string, client *http.Client) *Introspector {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Introspector{endpoint: endpoint, clientID: clientID, clientSecret: clientSecret, client: client}
+}
+
+// WithIntrospection introspects tokens with i after they are verified locally, e.g. to reject revoked tokens.
+// A token which isn't active is rejected.
+func WithIntrospection(i *Introspector) Option {
+	return func(v *Verifier) {
+		v.introspector = i
+	}
+}
+
+// Introspect returns the claims of an active token, the response members which are claims, like iss, sub, aud and exp,
+// are decoded into Claims and all members are decoded by JWT.UnmarshalClaims. Header and Signature are empty.
+// An error is returned if the token isn't active or expired.
+func (i *Introspector) Introspect(ctx context.Context, token string) (*JWT, error) {
+	return i.introspect(ctx, token, time.Now())
+}
+
+// introspect is Introspect checking the exp member at now, e.g. the clock of a Verifier.
+func (i *Introspector) introspect(ctx context.Context, token string, now time.Time) (*JWT, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create request - %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+
+	res, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request - %w", err)
+	}
+	defer res.Body.Close()
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+	b, err := readLimited(res.Body, defaultJWKSConfig.maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("read introspection response - %w", err)
+	}
+
+	var active struct {
+		Active bool `json:"active"`
+	}
+	if err := json.Unmarshal(b, &active); err != nil {
+		return nil, fmt.Errorf("unable to json decode introspection response of %v bytes, %w", len(b), err)
+	}
+	if !active.Active {
+		return nil, ErrInactive
+	}
+	var t JWT
+	if err := unmarshalClaims(b, &t.Claims); err != nil {
+		return nil, fmt.Errorf("unable to json decode introspection response of %v bytes, %w", len(b), err)
+	}
+	t.rawClaims = b
+	if t.Claims.EXP != 0 && t.Claims.EXP <= now.Unix() {
+		return nil, newExpiredError(t.Claims.EXP, now)
+	}
+	return &t, nil
+}
@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIntrospector(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	revoked := testToken(t, key, nil, map[string]interface{}{"jti": "revoked"})
+	past := time.Now().Add(-time.Hour * 2)
+	replayed := testToken(t, key, nil, map[string]interface{}{"iat": past.Unix(), "exp": past.Add(time.Hour).Unix()})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "rs" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch token := r.PostFormValue("token"); {
+		case token == "opaque":
+			fmt.Fprintf(w, `{"active": true, "sub": "1234", "aud": ["api"], "scope": "read", "exp": %v}`, time.Now().Add(time.Hour).Unix())
+		case token == "expired":
+			fmt.Fprintf(w, `{"active": true, "exp": %v}`, time.Now().Add(-time.Hour).Unix())
+		case token == replayed:
+			fmt.Fprintf(w, `{"active": true, "exp": %v}`, past.Add(time.Hour).Unix())
+		case token == revoked:
+			fmt.Fprint(w, `{"active": false}`)
+		default:
+			fmt.Fprint(w, `{"active": true}`)
+		}
+	}))
+	defer srv.Close()
+	i := NewIntrospector(srv.URL, "rs", "secret", nil)
+
+	token, err := i.Introspect(context.Background(), "opaque")
+	if err != nil {
+		t.Fatalf("introspect fail, %v", err)
+	}
+	var claims struct {
+		Scope string `json:"scope"`
+	}
+	if err := token.UnmarshalClaims(&claims); err != nil || token.Claims.SUB != "1234" || !token.Claims.AUD.Contains("api") || claims.Scope != "read" {
+		t.Errorf("unexpected claims %+v %+v, %v", token.Claims, claims, err)
+	}
+	for _, inactive := range []string{"expired", revoked} {
+		if _, err := i.Introspect(context.Background(), inactive); err == nil {
+			t.Errorf("inactive token %v not throwing error", inactive)
+		}
+	}
+	if _, err := NewIntrospector(srv.URL, "rs", "wrong", nil).Introspect(context.Background(), "opaque"); err == nil {
+		t.Errorf("unauthorized introspection not throwing error")
+	}
+
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithIntrospection(i))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(revoked); !errors.Is(err, ErrInactive) || IsRetryable(err) {
+		t.Errorf("revoked token not throwing permanent ErrInactive, %v", err)
+	}
+
+	ver, err = NewVerifier(keyGetterFunc(jwks), testClientID, WithIntrospection(i), WithClock(func() time.Time { return past }))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(replayed); err != nil {
+		t.Errorf("introspection not using the verifier clock, %v", err)
+	}
+
+	ver, err = NewVerifier(keyGetterFunc(jwks), testClientID, WithIntrospection(NewIntrospector(srv.URL, "rs", "wrong", nil)))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); !IsRetryable(err) || ErrorCode(err) != "introspection_unavailable" {
+		t.Errorf("failed introspection not retryable, %v", err)
+	}
+}
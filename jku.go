@@ -0,0 +1,124 @@
+package jwt
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJKUCaches is the maximal number of jku URLs whose keys a Verifier caches.
+const maxJKUCaches = 16
+
+// WithJKU verifies tokens with a jku header with the JSON Web Key Set at the jku URL, if it starts with one of prefixes,
+// e.g. https://auth.example.com/keys/. The scheme, host, port and path of the URL are matched, the prefixes must be https URLs
+// and are matched as is, so that a prefix ending with / allows the key sets of a directory. URLs with a query, a fragment,
+// user info or dot segments are rejected. Other tokens with a jku header are rejected, tokens without are verified
+// with the keys of the Verifier.
+// The keys of a jku URL are fetched by an HTTPKeyFetcher configured by opts, without retries unless set by opts,
+// and cached as the keys of the Verifier, without background refresh nor WithKeyCacheStore.
+// The keys of a URL are cached once they verified a token, for the 16 most recently used URLs, so that forged tokens
+// don't evict them. The keys of other URLs are fetched at most once per WithUnknownKeyRefresh interval, the default is one minute,
+// a non-positive interval doesn't limit the fetches.
+func WithJKU(prefixes []string, opts ...HTTPOption) Option {
+	return func(v *Verifier) {
+		allowed := make([]string, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			parsed, err := url.Parse(prefix)
+			if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+				continue
+			}
+			if parsed.Path == "" {
+				parsed.Path = "/"
+			}
+			allowed = append(allowed, "https://"+strings.ToLower(parsed.Host)+parsed.Path)
+		}
+		opts = append([]HTTPOption{WithRetry(0, 0, 0)}, opts...)
+		v.jku = &jkuCaches{prefixes: allowed, opts: opts, caches: make(map[string]*jkuCache)}
+	}
+}
+
+// jkuCaches holds the key caches of the jku URLs of verified tokens.
+type jkuCaches struct {
+	prefixes []string
+	opts     []HTTPOption
+
+	// mu guards caches and lastFetch
+	mu     sync.Mutex
+	caches map[string]*jkuCache
+	// lastFetch is the time a new cache of a jku URL which isn't cached was last returned
+	lastFetch time.Time
+}
+
+// jkuCache is the key cache of a jku URL and the time it was last used.
+type jkuCache struct {
+	keys *keyCache
+	used time.Time
+}
+
+// allowed reports whether the jku URL u starts with one of the prefixes and has no query, fragment, user info or dot segments.
+func (j *jkuCaches) allowed(u string) bool {
+	if strings.ContainsAny(u, "?#") {
+		return false
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil || parsed.Opaque != "" || parsed.RawPath != "" {
+		return false
+	}
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	normalized := "https://" + strings.ToLower(parsed.Host) + parsed.Path
+	for _, prefix := range j.prefixes {
+		if strings.HasPrefix(normalized, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cache returns the key cache of the jku URL u, a new empty cache with config if u isn't cached,
+// or a ValidationError if u isn't allowed or a URL which isn't cached was fetched less than the unknown key refresh ago.
+func (j *jkuCaches) cache(u string, config cacheConfig) (*keyCache, error) {
+	if !j.allowed(u) {
+		return nil, &ValidationError{Kind: KindKeyNotFound, Claim: "jku", Actual: u, Err: fmt.Errorf("%w, jku %v is not allowed", ErrKeyNotFound, u)}
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if c, ok := j.caches[u]; ok {
+		c.used = time.Now()
+		return c.keys, nil
+	}
+	if config.unknownKeyRefresh > 0 && time.Since(j.lastFetch) < config.unknownKeyRefresh {
+		return nil, &ValidationError{Kind: KindKeyNotFound, Claim: "jku", Actual: u,
+			Err: fmt.Errorf("%w, jku %v not fetched, a jku was fetched less than %v ago", ErrKeyNotFound, u, config.unknownKeyRefresh)}
+	}
+	j.lastFetch = time.Now()
+	config.background = false
+	config.store, config.storeKey = nil, ""
+	// the keys are fetched once for a new cache, an unknown kid doesn't refresh them again
+	return &keyCache{keyFetcher: NewHTTPKeyFetcher(u, j.opts...), config: config, lastUnknownRefresh: time.Now()}, nil
+}
+
+// add caches keys, which verified a token, as the keys of the jku URL u unless u is cached,
+// evicting the least recently used URL if there are maxJKUCaches.
+func (j *jkuCaches) add(u string, keys *keyCache) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.caches[u]; ok {
+		return
+	}
+	if len(j.caches) >= maxJKUCaches {
+		var oldest string
+		for cached, c := range j.caches {
+			if oldest == "" || c.used.Before(j.caches[oldest].used) {
+				oldest = cached
+			}
+		}
+		delete(j.caches, oldest)
+	}
+	j.caches[u] = &jkuCache{keys: keys, used: time.Now()}
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithJKU(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("content-type", "application/json")
+		w.Write([]byte(jwks))
+	}))
+	defer srv.Close()
+
+	other, otherJWKS := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(otherJWKS), testClientID, WithJKU([]string{srv.URL + "/keys/"}, WithHTTPClient(srv.Client())))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": srv.URL + "/keys/a"}, nil)); err != nil {
+			t.Fatalf("jku token parse fail, %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 key request, got %v", requests)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, other, nil, nil)); err != nil {
+		t.Errorf("token without jku parse fail, %v", err)
+	}
+
+	for _, jku := range []string{
+		"http://127.0.0.1/keys/a", "https://attacker.example.com/keys/a", "https://user@" + srv.URL[len("https://"):] + "/keys/a", "::",
+		srv.URL + "/other", srv.URL + "/keys", srv.URL + "/keys/a?x=1", srv.URL + "/keys/a?", srv.URL + "/keys/a#x",
+		srv.URL + "/keys/../other", srv.URL + "/keys/%2e%2e/other", "https://127.0.0.1:1/keys/a",
+	} {
+		_, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": jku}, nil))
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Claim != "jku" || !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("jku %v: expected disallowed jku, got %v", jku, err)
+		}
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, other, map[string]interface{}{"jku": srv.URL + "/keys/a"}, nil)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("token of other key with jku, expected invalid signature, got %v", err)
+	}
+
+	// jku URLs which aren't cached are fetched at most once per unknown key refresh interval
+	requests = 0
+	for i := 0; i < maxJKUCaches+4; i++ {
+		forged := testToken(t, other, map[string]interface{}{"jku": fmt.Sprintf("%v/keys/%v", srv.URL, i)}, nil)
+		if _, err := ver.ParseAndVerify(forged); !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("forged token, expected rate limited jku, got %v", err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("expected no key request within the unknown key refresh interval, got %v", requests)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": srv.URL + "/keys/a"}, nil)); err != nil {
+		t.Errorf("cached jku token parse fail after forged tokens, %v", err)
+	}
+
+	// forged tokens with other jku URLs don't evict the keys of verified tokens
+	ver, err = NewVerifier(keyGetterFunc(otherJWKS), testClientID, WithJKU([]string{srv.URL + "/keys/"}, WithHTTPClient(srv.Client())),
+		WithUnknownKeyRefresh(0))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": srv.URL + "/keys/a"}, nil)); err != nil {
+		t.Fatalf("jku token parse fail, %v", err)
+	}
+	for i := 0; i < maxJKUCaches+4; i++ {
+		forged := testToken(t, other, map[string]interface{}{"jku": fmt.Sprintf("%v/keys/%v", srv.URL, i)}, nil)
+		if _, err := ver.ParseAndVerify(forged); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("forged token, expected invalid signature, got %v", err)
+		}
+	}
+	if n := len(ver.jku.caches); n != 1 {
+		t.Errorf("expected the keys of 1 jku URL cached, got %v", n)
+	}
+	requests = 0
+	for _, jku := range []string{srv.URL + "/keys/a", srv.URL + "/keys/b"} {
+		if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": jku}, nil)); err != nil {
+			t.Errorf("jku %v token parse fail after forged tokens, %v", jku, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 key request for the new jku URL, got %v", requests)
+	}
+
+	ver, err = NewVerifier(keyGetterFunc(otherJWKS), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"jku": srv.URL + "/keys/a"}, nil)); err == nil {
+		t.Errorf("jku honored without WithJKU")
+	}
+}
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DecryptionKeyFunc returns the private key of the kid header of an encrypted token, e.g. an *rsa.PrivateKey
+// or a crypto.Decrypter of a key held in an HSM. kid is empty if the token has no kid header.
+type DecryptionKeyFunc func(ctx context.Context, kid string) (crypto.Decrypter, error)
+
+// WithDecryption accepts tokens encrypted as a JWE in compact serialization, whose content is the signed token,
+// e.g. the ID tokens of providers configured to encrypt them. The content encryption key is decrypted with the RSA key returned by keys
+// and alg RSA-OAEP or RSA-OAEP-256, the content with enc A128GCM, A192GCM or A256GCM. The signed token is verified as usual,
+// and signed tokens which aren't encrypted are accepted too. A failed decryption is a ValidationError of KindMalformed,
+// an error returned by keys is returned as is.
+func WithDecryption(keys DecryptionKeyFunc) Option {
+	return func(v *Verifier) {
+		v.decryptionKeys = keys
+	}
+}
+
+// jweHeader is the protected header of a JWE.
+type jweHeader struct {
+	ALG string `json:"alg"`
+	ENC string `json:"enc"`
+	KID string `json:"kid"`
+	ZIP string `json:"zip"`
+}
+
+// decryptJWE returns the content of the JWE tokenString, in compact serialization, decrypted with a key returned by keys.
+func decryptJWE(ctx context.Context, tokenString string, keys DecryptionKeyFunc) (string, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 5 {
+		return "", &ValidationError{Kind: KindMalformed, Err: fmt.Errorf("%w, JWE of %v parts", ErrMalformed, len(parts))}
+	}
+	malformed := func(claim, actual string, err error) error {
+		return &ValidationError{Kind: KindMalformed, Claim: claim, Actual: actual, Err: wrapSentinel(ErrMalformed, ", decrypt token - ", err)}
+	}
+	var decoded [5][]byte
+	for i, part := range parts {
+		var err error
+		if decoded[i], err = base64.RawURLEncoding.DecodeString(part); err != nil {
+			return "", malformed("", "", fmt.Errorf("decode part %v - %w", i, err))
+		}
+	}
+	var header jweHeader
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return "", malformed("", "", fmt.Errorf("unmarshal header - %w", err))
+	}
+
+	var hash crypto.Hash
+	switch header.ALG {
+	case "RSA-OAEP":
+		hash = crypto.SHA1
+	case "RSA-OAEP-256":
+		hash = crypto.SHA256
+	default:
+		return "", malformed("alg", header.ALG, fmt.Errorf("unsupported alg %v", header.ALG))
+	}
+	var keySize int
+	switch header.ENC {
+	case "A128GCM":
+		keySize = 16
+	case "A192GCM":
+		keySize = 24
+	case "A256GCM":
+		keySize = 32
+	default:
+		return "", malformed("enc", header.ENC, fmt.Errorf("unsupported enc %v", header.ENC))
+	}
+	if header.ZIP != "" {
+		return "", malformed("zip", header.ZIP, fmt.Errorf("unsupported zip %v", header.ZIP))
+	}
+
+	key, err := keys(ctx, header.KID)
+	if err != nil {
+		return "", fmt.Errorf("get decryption key %v - %w", header.KID, err)
+	}
+	cek, err := key.Decrypt(rand.Reader, decoded[1], &rsa.OAEPOptions{Hash: hash})
+	if err != nil || len(cek) != keySize {
+		return "", malformed("", "", fmt.Errorf("decrypt content encryption key with kid %v", header.KID))
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return "", malformed("", "", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", malformed("", "", err)
+	}
+	if len(decoded[2]) != gcm.NonceSize() || len(decoded[4]) != gcm.Overhead() {
+		return "", malformed("", "", fmt.Errorf("invalid iv or tag size"))
+	}
+	// the additional authenticated data is the encoded protected header
+	content, err := gcm.Open(nil, decoded[2], append(decoded[3], decoded[4]...), []byte(parts[0]))
+	if err != nil {
+		return "", malformed("", "", fmt.Errorf("decrypt content - %w", err))
+	}
+	return string(content), nil
+}
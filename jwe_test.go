@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testJWE encrypts content as a compact JWE with the public key of key, alg RSA-OAEP or RSA-OAEP-256 and enc A256GCM.
+func testJWE(t *testing.T, key *rsa.PrivateKey, alg, content string) string {
+	t.Helper()
+	var h hash.Hash = sha256.New()
+	if alg == "RSA-OAEP" {
+		h = sha1.New()
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"` + alg + `","enc":"A256GCM","kid":"enc","cty":"JWT"}`))
+	cek, iv := make([]byte, 32), make([]byte, 12)
+	rand.Read(cek)
+	rand.Read(iv)
+	encryptedKey, err := rsa.EncryptOAEP(h, rand.Reader, &key.PublicKey, cek, nil)
+	if err != nil {
+		t.Fatalf("encrypt key failed, %v", err)
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatalf("new cipher failed, %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("new gcm failed, %v", err)
+	}
+	sealed := gcm.Seal(nil, iv, []byte(content), []byte(header))
+	ciphertext, tag := sealed[:len(sealed)-16], sealed[len(sealed)-16:]
+	parts := []string{header}
+	for _, b := range [][]byte{encryptedKey, iv, ciphertext, tag} {
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(b))
+	}
+	return strings.Join(parts, ".")
+}
+
+func TestWithDecryption(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	encKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	keyErr := errors.New("key unavailable")
+	keys := func(ctx context.Context, kid string) (crypto.Decrypter, error) {
+		if kid != "enc" {
+			return nil, keyErr
+		}
+		return encKey, nil
+	}
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithDecryption(keys))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	signed := testToken(t, key, nil, map[string]interface{}{"sub": "nested", "exp": time.Now().Add(time.Hour).Unix()})
+
+	for _, alg := range []string{"RSA-OAEP", "RSA-OAEP-256"} {
+		token, err := ver.ParseAndVerify(testJWE(t, encKey, alg, signed))
+		if err != nil {
+			t.Fatalf("%v token parse fail, %v", alg, err)
+		}
+		if token.Claims.SUB != "nested" {
+			t.Errorf("unexpected claims %+v", token.Claims)
+		}
+	}
+	if _, err := ver.ParseAndVerify(signed); err != nil {
+		t.Errorf("unencrypted token parse fail, %v", err)
+	}
+
+	encrypted := testJWE(t, encKey, "RSA-OAEP-256", signed)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"tampered tag", encrypted[:len(encrypted)-2] + "AA"},
+		{"other key", testJWE(t, otherKey, "RSA-OAEP-256", signed)},
+		{"unsigned content", testJWE(t, encKey, "RSA-OAEP-256", "content")},
+		{"unsupported alg", strings.Replace(encrypted, strings.Split(encrypted, ".")[0], base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA1_5","enc":"A256GCM","kid":"enc"}`)), 1)},
+	}
+	for _, test := range tests {
+		_, err := ver.ParseAndVerify(test.token)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Kind != KindMalformed {
+			t.Errorf("%v: expected malformed token, got %v", test.name, err)
+		}
+	}
+
+	noKid := strings.Replace(encrypted, strings.Split(encrypted, ".")[0], base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`)), 1)
+	if _, err := ver.ParseAndVerify(noKid); !errors.Is(err, keyErr) {
+		t.Errorf("expected key error, got %v", err)
+	}
+}
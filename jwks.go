@@ -0,0 +1,323 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// ParseJWKS returns the RSA (kty RSA), Ed25519 (kty OKP) and P-256 (kty EC) public keys of a JSON Web Key Set, mapped by their key ID,
+// or by their Thumbprint if they have none, e.g. the single key of an issuer which omits kid.
+// Keys of other types, and OKP and EC keys on curves other than Ed25519 and P-256, are ignored.
+// If a key has an x5c certificate chain, the public key of its first certificate must match the key.
+// Keys with use "enc", or with key_ops lacking "verify", are ignored as well.
+// The key set is limited to 1 MiB and 100 keys.
+func ParseJWKS(r io.Reader) (map[string]crypto.PublicKey, error) {
+	keys, err := defaultJWKSConfig.parse(r)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]crypto.PublicKey, len(keys))
+	for kid, k := range keys {
+		m[kid] = k.key
+	}
+	return m, nil
+}
+
+// verificationKey is a cached public key.
+type verificationKey struct {
+	key crypto.PublicKey
+	alg string // the alg declared by the JWK, empty if any alg of the key type may be used
+}
+
+// jwksConfig holds the JWKS parsing configuration set by Verifier options.
+type jwksConfig struct {
+	// roots verifies the x5c certificate chain of keys if non-nil, keys without x5c are then ignored
+	roots *x509.CertPool
+	// maxSize and maxKeys limit the size in bytes and the number of keys of a key set if positive
+	maxSize int64
+	maxKeys int
+	format  KeyFormat
+	// anchors verify the signed key set if non-nil
+	anchors map[string]crypto.PublicKey
+	// clock is the time at which the expiration of a signed key set is checked, time.Now if nil
+	clock func() time.Time
+}
+
+var defaultJWKSConfig = jwksConfig{
+	maxSize: 1 << 20,
+	maxKeys: 100,
+}
+
+// LimitError is returned when a key set exceeds its size or key count limit.
+type LimitError struct {
+	Limit string // "size" or "keys"
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("key set exceeds %v limit of %v", e.Limit, e.Max)
+}
+
+// readLimited reads r to EOF, returning a *LimitError if it has more than max bytes.
+// A non-positive max doesn't limit r.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(r)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, &LimitError{Limit: "size", Max: max}
+	}
+	return b, nil
+}
+
+func (c jwksConfig) parse(r io.Reader) (map[string]verificationKey, error) {
+	m := make(map[string]verificationKey)
+	b, err := readLimited(r, c.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if c.anchors != nil {
+		if b, err = c.verifySigned(b); err != nil {
+			return nil, err
+		}
+	}
+	if c.format == FormatX509 || c.format == FormatAuto && !isJWKS(b) {
+		return c.parseX509(b)
+	}
+	jwks, err := decodeJWKS(bytes.NewReader(b))
+
+	if err != nil {
+		return nil, err
+	}
+	if c.maxKeys > 0 && len(jwks.Keys) > c.maxKeys {
+		return nil, &LimitError{Limit: "keys", Max: int64(c.maxKeys)}
+	}
+
+	for _, v := range jwks.Keys {
+		if !v.canVerify() || c.format == FormatSPIFFEBundle && v.USE != "jwt-svid" {
+			continue
+		}
+		var key crypto.PublicKey
+		switch v.KTY {
+		case "RSA", "":
+			key, err = parseRSAJWK(v)
+		case "OKP":
+			// Ed448 and X25519 keys of the set can't verify tokens
+			if v.CRV != "Ed25519" {
+				continue
+			}
+			key, err = parseOKPJWK(v)
+		case "EC":
+			// P-384 and P-521 keys of the set can't verify tokens
+			if v.CRV != "P-256" {
+				continue
+			}
+			key, err = parseECJWK(v)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(v.X5C) == 0 && c.roots != nil {
+			continue
+		}
+		if len(v.X5C) > 0 {
+			if err := c.checkX5C(v, key); err != nil {
+				return nil, err
+			}
+		}
+		kid := v.KID
+		if kid == "" {
+			if kid, err = Thumbprint(key); err != nil {
+				return nil, err
+			}
+		}
+		m[kid] = verificationKey{key: key, alg: v.ALG}
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no public keys %v", jwks)
+	}
+	return m, nil
+}
+
+// checkX5C returns an error if the x5c leaf certificate of v doesn't have key,
+// or if roots is set and the chain isn't valid.
+func (c jwksConfig) checkX5C(v jwk, key crypto.PublicKey) error {
+	certs := make([]*x509.Certificate, len(v.X5C))
+	for i, enc := range v.X5C {
+		der, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return fmt.Errorf("unable to base64 decode x5c of JWK %v, %w", v.KID, err)
+		}
+		if certs[i], err = x509.ParseCertificate(der); err != nil {
+			return fmt.Errorf("unable to parse x5c of JWK %v, %w", v.KID, err)
+		}
+	}
+
+	leafKey, ok := certs[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !leafKey.Equal(key) {
+		return fmt.Errorf("x5c certificate key doesn't match JWK %v", v.KID)
+	}
+
+	if c.roots == nil {
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         c.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("verify x5c of JWK %v, %w", v.KID, err)
+	}
+	return nil
+}
+
+// canVerify reports whether the use and key_ops of v allow verifying signatures.
+func (v jwk) canVerify() bool {
+	if v.USE == "enc" {
+		return false
+	}
+	if v.KeyOps == nil {
+		return true
+	}
+	for _, op := range v.KeyOps {
+		if op == "verify" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRSAJWK(v jwk) (*rsa.PublicKey, error) {
+	if v.E == "" || v.N == "" {
+		return nil, fmt.Errorf("missing info in JWK %v", v)
+	}
+	decodedN, err := base64.RawURLEncoding.DecodeString(v.N)
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode jwk n value %v, %w", v.N, err)
+	}
+	decodedE, err := base64.RawURLEncoding.DecodeString(v.E)
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode jwk e value %v, %w", v.E, err)
+	}
+
+	n := big.NewInt(0).SetBytes(decodedN)
+	e := big.NewInt(0).SetBytes(decodedE).Int64()
+
+	return &rsa.PublicKey{
+		N: n,
+		E: int(e),
+	}, nil
+}
+
+func parseOKPJWK(v jwk) (ed25519.PublicKey, error) {
+	if v.X == "" {
+		return nil, fmt.Errorf("missing info in JWK %v", v)
+	}
+	if v.CRV != "Ed25519" {
+		return nil, fmt.Errorf("unsupported OKP curve %v", v.CRV)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(v.X)
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode jwk x value %v, %w", v.X, err)
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 key size %v", len(x))
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+func parseECJWK(v jwk) (*ecdsa.PublicKey, error) {
+	if v.X == "" || v.Y == "" {
+		return nil, fmt.Errorf("missing info in JWK %v", v)
+	}
+	if v.CRV != "P-256" {
+		return nil, fmt.Errorf("unsupported EC curve %v", v.CRV)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(v.X)
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode jwk x value %v, %w", v.X, err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(v.Y)
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode jwk y value %v, %w", v.Y, err)
+	}
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("invalid P-256 point of JWK %v", v.KID)
+	}
+	return key, nil
+}
+
+// checkKeys returns an error if keys is empty or has a key type which can't verify tokens.
+func checkKeys(keys map[string]crypto.PublicKey) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no public keys")
+	}
+	for kid, k := range keys {
+		switch k := k.(type) {
+		case *rsa.PublicKey, ed25519.PublicKey:
+		case *ecdsa.PublicKey:
+			if k.Curve != elliptic.P256() {
+				return fmt.Errorf("unsupported curve %v for key %v", k.Curve.Params().Name, kid)
+			}
+		default:
+			return fmt.Errorf("unsupported key type %T for key %v", k, kid)
+		}
+	}
+	return nil
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	ALG    string   `json:"alg"`
+	KTY    string   `json:"kty"`
+	USE    string   `json:"use"`
+	KeyOps []string `json:"key_ops"`
+	N      string   `json:"n"`
+	E      string   `json:"e"`
+	CRV    string   `json:"crv"`
+	X      string   `json:"x"`
+	Y      string   `json:"y"`
+	KID    string   `json:"kid"`
+	X5C    []string `json:"x5c"`
+}
+
+func decodeJWKS(r io.Reader) (*jwks, error) {
+	var keys jwks
+	if err := json.NewDecoder(r).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("decode json %v - %w", r, err)
+	}
+	if keys.Keys == nil {
+		return nil, fmt.Errorf("empty key list %v", r)
+	}
+	return &keys, nil
+}
@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert returns a certificate of key signed by parent, or a self signed CA certificate if parent is nil.
+func testCert(t *testing.T, key *rsa.PrivateKey, parent *x509.Certificate, parentKey *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate failed, %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate failed, %v", err)
+	}
+	return cert
+}
+
+func TestParseJWKSX5C(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	ca := testCert(t, caKey, nil, nil)
+	leaf := testCert(t, key, ca, caKey)
+
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	x5c := fmt.Sprintf(`["%v", "%v"]`, base64.StdEncoding.EncodeToString(leaf.Raw), base64.StdEncoding.EncodeToString(ca.Raw))
+	jwk := fmt.Sprintf(`{"keys": [{"kty":"RSA","kid":"test","e":"AQAB","n":"%v","x5c":%v}, {"kty":"RSA","kid":"no-x5c","e":"AQAB","n":"%v"}]}`, n, x5c, n)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	keys, err := jwksConfig{roots: roots}.parse(strings.NewReader(jwk))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	if len(keys) != 1 || keys["test"].key == nil {
+		t.Errorf("expected only the x5c key, got %v", keys)
+	}
+
+	if _, err := (jwksConfig{roots: x509.NewCertPool()}).parse(strings.NewReader(jwk)); err == nil {
+		t.Errorf("untrusted x5c chain not throwing error")
+	}
+
+	mismatch := strings.Replace(jwk, n, base64.RawURLEncoding.EncodeToString(caKey.N.Bytes()), 1)
+	if _, err := ParseJWKS(strings.NewReader(mismatch)); err == nil {
+		t.Errorf("x5c key mismatch not throwing error")
+	}
+}
+
+func TestParseJWKSUseAndAlg(t *testing.T) {
+	jwk := strings.Replace(validKey, `"kty":"RSA"`, `"kty":"RSA","alg":"RS256"`, 1)
+	jwk = strings.Replace(jwk, `]}`, `,{"kty":"RSA","kid":"enc","use":"enc","e":"AQAB","n":"AQAB"},{"kty":"RSA","kid":"sign","key_ops":["sign"],"e":"AQAB","n":"AQAB"}]}`, 1)
+	keys, err := jwksConfig{}.parse(strings.NewReader(jwk))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	if len(keys) != 1 || keys["f73e9e2b-242e-4842-8809-65ba74800972"].alg != "RS256" {
+		t.Errorf("expected only the RS256 verification key, got %v", keys)
+	}
+
+	// the key only allows EdDSA, so the RS256 token is rejected before its signature is verified
+	ver, err := NewVerifier(keyGetterFunc(strings.Replace(validKey, `"kty":"RSA"`, `"kty":"RSA","alg":"EdDSA"`, 1)), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err == nil || !strings.Contains(err.Error(), "doesn't match key alg") {
+		t.Errorf("expected key alg mismatch, got %v", err)
+	}
+}
+
+func TestParseJWKSUnsupportedOKPCurves(t *testing.T) {
+	jwk := strings.Replace(validKey, `]}`, `,{"kty":"OKP","crv":"Ed448","kid":"ed448","x":"X9dEm1m0Yf0s54fsYWrUah2hNCSFpw4fig6nXYDpZ3jt8SR2m0bHBhvWeD3x5Q9s0foavq_oJWGA"},`+
+		`{"kty":"OKP","crv":"X25519","kid":"x25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}]}`, 1)
+	keys, err := ParseJWKS(strings.NewReader(jwk))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	if _, ok := keys["f73e9e2b-242e-4842-8809-65ba74800972"]; !ok || len(keys) != 1 {
+		t.Errorf("expected only the RSA key, got %v", keys)
+	}
+}
+
+func TestParseJWKSUnsupportedECCurves(t *testing.T) {
+	jwk := strings.Replace(validKey, `]}`, `,{"kty":"EC","crv":"P-384","kid":"p384","x":"AQ","y":"AQ"},{"kty":"EC","crv":"P-521","kid":"p521","x":"AQ","y":"AQ"}]}`, 1)
+	keys, err := ParseJWKS(strings.NewReader(jwk))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	if _, ok := keys["f73e9e2b-242e-4842-8809-65ba74800972"]; !ok || len(keys) != 1 {
+		t.Errorf("expected only the RSA key, got %v", keys)
+	}
+}
+
+func TestJWKSLimits(t *testing.T) {
+	var limitErr *LimitError
+	if _, err := NewVerifier(keyGetterFunc(validKey), testClientID, WithJWKSLimits(100, 0)); !errors.As(err, &limitErr) || limitErr.Limit != "size" {
+		t.Errorf("expected size LimitError, got %v", err)
+	}
+
+	twoKeys := strings.Replace(validKey, `]}`, `,{"kty":"oct","kid":"other"}]}`, 1)
+	if _, err := NewVerifier(keyGetterFunc(twoKeys), testClientID, WithJWKSLimits(0, 1)); !errors.As(err, &limitErr) || limitErr.Limit != "keys" {
+		t.Errorf("expected keys LimitError, got %v", err)
+	}
+	if _, err := NewVerifier(keyGetterFunc(twoKeys), testClientID); err != nil {
+		t.Errorf("key set within default limits fail, %v", err)
+	}
+}
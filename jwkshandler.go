@@ -0,0 +1,137 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// JWKSHandler is an http.Handler serving the public keys of Signers as a JSON Web Key Set,
+// e.g. the jwks_uri of the tokens they sign. It may be used concurrently.
+type JWKSHandler struct {
+	maxAge time.Duration
+	// doc holds the current *jwksDocument
+	doc atomic.Value
+}
+
+// jwksDocument is a rendered JSON Web Key Set and its entity tag.
+type jwksDocument struct {
+	body []byte
+	etag string
+}
+
+// publishedJWK is a public JSON Web Key served by JWKSHandler.
+type publishedJWK struct {
+	KTY string `json:"kty"`
+	USE string `json:"use"`
+	ALG string `json:"alg"`
+	KID string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	CRV string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// NewJWKSHandler returns a JWKSHandler serving the public keys of signers with a Cache-Control max-age of maxAge.
+// maxAge should be shorter than the time a key is published before it signs tokens, so that verifiers fetch it in time.
+func NewJWKSHandler(maxAge time.Duration, signers ...*Signer) (*JWKSHandler, error) {
+	h := &JWKSHandler{maxAge: maxAge}
+	if err := h.SetSigners(signers...); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SetSigners replaces the served keys by the public keys of signers, e.g. to publish the key of a new Signer
+// before it signs tokens and to keep the previous key until its tokens expire.
+// The kid of a key is the kid of its Signer, or the RFC 7638 thumbprint of the key if empty, so that it doesn't change across rotations.
+// Signers with the same kid must have the same key.
+func (h *JWKSHandler) SetSigners(signers ...*Signer) error {
+	keys := make(map[string]publishedJWK, len(signers))
+	for _, s := range signers {
+		kid := s.kid
+		if kid == "" {
+			tp, err := Thumbprint(s.public)
+			if err != nil {
+				return err
+			}
+			kid = tp
+		}
+		key, err := publicJWK(kid, s.alg, s.public)
+		if err != nil {
+			return err
+		}
+		if other, ok := keys[kid]; ok && other != key {
+			return fmt.Errorf("signers with different keys for kid %v", kid)
+		}
+		keys[kid] = key
+	}
+	doc := struct {
+		Keys []publishedJWK `json:"keys"`
+	}{Keys: make([]publishedJWK, 0, len(keys))}
+	for _, key := range keys {
+		doc.Keys = append(doc.Keys, key)
+	}
+	sort.Slice(doc.Keys, func(i, j int) bool { return doc.Keys[i].KID < doc.Keys[j].KID })
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal keys - %w", err)
+	}
+	sum := sha256.Sum256(body)
+	h.doc.Store(&jwksDocument{body: body, etag: `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`})
+	return nil
+}
+
+// ServeHTTP serves the key set to GET and HEAD requests, with an ETag for conditional requests.
+func (h *JWKSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	doc := h.doc.Load().(*jwksDocument)
+	w.Header().Set("cache-control", "public, max-age="+strconv.Itoa(int(h.maxAge/time.Second)))
+	w.Header().Set("etag", doc.etag)
+	if r.Header.Get("if-none-match") == doc.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("content-length", strconv.Itoa(len(doc.body)))
+	if r.Method == http.MethodGet {
+		w.Write(doc.body)
+	}
+}
+
+// publicJWK returns the JSON Web Key of an *rsa.PublicKey, ed25519.PublicKey or P-256 *ecdsa.PublicKey key.
+func publicJWK(kid, alg string, key crypto.PublicKey) (publishedJWK, error) {
+	k := publishedJWK{USE: "sig", ALG: alg, KID: kid}
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		k.KTY = "RSA"
+		k.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		k.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		k.KTY, k.CRV = "OKP", "Ed25519"
+		k.X = base64.RawURLEncoding.EncodeToString(pub)
+	case *ecdsa.PublicKey:
+		k.KTY, k.CRV = "EC", "P-256"
+		k.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		k.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	default:
+		return publishedJWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+	return k, nil
+}
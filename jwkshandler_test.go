@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	var signers []*Signer
+	for _, v := range []struct {
+		key interface{}
+		kid string
+	}{{rsaKey, "rsa"}, {ecKey, "ec"}, {edKey, ""}} {
+		s, err := NewSigner(v.key, v.kid)
+		if err != nil {
+			t.Fatalf("New Signer failed, %v", err)
+		}
+		signers = append(signers, s)
+	}
+	h, err := NewJWKSHandler(time.Hour, signers...)
+	if err != nil {
+		t.Fatalf("New JWKS handler failed, %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ver, err := NewVerifierContext(context.Background(), NewHTTPKeyFetcher(srv.URL), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	thumbprint, err := Thumbprint(signers[2].Public())
+	if err != nil {
+		t.Fatalf("thumbprint failed, %v", err)
+	}
+	for _, s := range signers[:2] {
+		token, err := s.Sign(Claims{ISS: "https://accounts.google.com", AUD: Audience{testClientID}, EXP: time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("sign failed, %v", err)
+		}
+		if _, err := ver.ParseAndVerify(token); err != nil {
+			t.Errorf("%v token parse fail, %v", s.Alg(), err)
+		}
+	}
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed, %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatalf("read body failed, %v", err)
+	}
+	if !strings.Contains(string(body), `"kid":"`+thumbprint+`"`) {
+		t.Errorf("key without kid not published with its thumbprint, %s", body)
+	}
+	if cc := res.Header.Get("cache-control"); cc != "public, max-age=3600" {
+		t.Errorf("unexpected cache-control %v", cc)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("if-none-match", res.Header.Get("etag"))
+	notModified, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed, %v", err)
+	}
+	notModified.Body.Close()
+	if notModified.StatusCode != http.StatusNotModified {
+		t.Errorf("expected not modified, got %v", notModified.StatusCode)
+	}
+
+	if err := h.SetSigners(signers[0]); err != nil {
+		t.Fatalf("set signers failed, %v", err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `"kid":"rsa"`) || strings.Contains(body, `"kid":"ec"`) || rec.Header().Get("etag") == res.Header.Get("etag") {
+		t.Errorf("unexpected rotated key set %v", body)
+	}
+
+	other, err := NewSigner(edKey, "rsa")
+	if err != nil {
+		t.Fatalf("New Signer failed, %v", err)
+	}
+	if err := h.SetSigners(signers[0], other); err == nil {
+		t.Errorf("different keys with the same kid not throwing error")
+	}
+}
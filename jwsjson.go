@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// maxJSONSignatures is the maximal number of signatures of a token in general JWS JSON serialization.
+const maxJSONSignatures = 8
+
+// WithJSONSerialization accepts tokens in the flattened and general JWS JSON serializations of RFC 7515,
+// besides the compact serialization, e.g. the signed statements of OpenID Federation.
+// A token in general serialization is valid if one of its signatures is, the signatures are verified in order.
+// The unprotected header parameters are ignored as they aren't signed, a token without kid in its protected header
+// is verified with every cached key, see WithMaxKeyAttempts.
+func WithJSONSerialization() Option {
+	return func(v *Verifier) {
+		v.jsonSerialization = true
+	}
+}
+
+// jwsSignature is a signature of a token in JWS JSON serialization.
+type jwsSignature struct {
+	Protected string          `json:"protected"`
+	Header    json.RawMessage `json:"header"`
+	Signature *string         `json:"signature"`
+}
+
+// compactSerializations returns the compact serializations of the signatures of a token in JWS JSON serialization.
+func compactSerializations(tokenString string) ([]string, error) {
+	var t struct {
+		Payload *string `json:"payload"`
+		jwsSignature
+		Signatures []jwsSignature `json:"signatures"`
+	}
+	if err := json.Unmarshal([]byte(tokenString), &t); err != nil {
+		return nil, fmt.Errorf("unmarshal JSON serialization - %w", err)
+	}
+	if t.Payload == nil {
+		return nil, fmt.Errorf("JSON serialization without payload")
+	}
+	signatures := t.Signatures
+	if t.Signature != nil {
+		if signatures != nil {
+			return nil, fmt.Errorf("JSON serialization with both flattened and general signatures")
+		}
+		signatures = []jwsSignature{t.jwsSignature}
+	}
+	if len(signatures) == 0 || len(signatures) > maxJSONSignatures {
+		return nil, fmt.Errorf("JSON serialization with %v signatures, expected 1 to %v", len(signatures), maxJSONSignatures)
+	}
+	compact := make([]string, len(signatures))
+	for i, s := range signatures {
+		if s.Signature == nil || s.Protected == "" {
+			return nil, fmt.Errorf("JSON serialization signature %v without protected header or signature", i)
+		}
+		compact[i] = s.Protected + "." + *t.Payload + "." + *s.Signature
+	}
+	return compact, nil
+}
+
+// verifyJSON verifies a token in JWS JSON serialization as verify, with each of its signatures,
+// and returns the failures of the signatures which fail the signature policy.
+func (v *Verifier) verifyJSON(ctx context.Context, tokenString string, all bool, report *Report) (*JWT, []error) {
+	compact, err := compactSerializations(tokenString)
+	if err != nil {
+		err = &ValidationError{Kind: KindMalformed, Err: wrapSentinel(ErrMalformed, ", ", err)}
+		if report != nil {
+			report.Checks = append(report.Checks, CheckResult{Name: "parse", Err: err})
+		}
+		return nil, []error{err}
+	}
+	tokens := make([]*JWT, len(compact))
+	errs := make([][]error, len(compact))
+	for i, c := range compact {
+		tokens[i], errs[i] = v.verify(ctx, c, all, report)
+		if len(errs[i]) == 0 && !v.signaturePolicy.all && v.signaturePolicy.kids == nil {
+			return tokens[i], nil
+		}
+	}
+	return v.signaturePolicy.apply(tokens, errs)
+}
+
+// SignaturePolicy selects the signatures of a token in general JWS JSON serialization which must be valid, see WithSignaturePolicy.
+type SignaturePolicy struct {
+	all  bool
+	kids []string
+}
+
+// The signature policies accepting a token if any or all of its signatures are valid.
+var (
+	AnySignature  = SignaturePolicy{}
+	AllSignatures = SignaturePolicy{all: true}
+)
+
+// SignaturesByKID returns a SignaturePolicy accepting a token with a valid signature with each of the kid headers kids,
+// the other signatures are ignored.
+func SignaturesByKID(kids ...string) SignaturePolicy {
+	return SignaturePolicy{kids: append([]string{}, kids...)}
+}
+
+// WithSignaturePolicy sets the signatures of a token in general JWS JSON serialization which must be valid,
+// the default is AnySignature. It requires WithJSONSerialization.
+func WithSignaturePolicy(policy SignaturePolicy) Option {
+	return func(v *Verifier) {
+		v.signaturePolicy = policy
+	}
+}
+
+// apply returns the token of the first valid signature, with the failures of the signatures tokens and errs violating p.
+func (p SignaturePolicy) apply(tokens []*JWT, errs [][]error) (*JWT, []error) {
+	first := -1
+	for i := range tokens {
+		if len(errs[i]) == 0 {
+			first = i
+			break
+		}
+	}
+	switch {
+	case p.all:
+		for i := range tokens {
+			if len(errs[i]) > 0 {
+				return tokens[i], errs[i]
+			}
+		}
+	case p.kids != nil:
+		for _, kid := range p.kids {
+			signed := -1
+			for i, token := range tokens {
+				if token == nil || token.Header.KID != kid {
+					continue
+				}
+				if len(errs[i]) == 0 {
+					signed = i
+					break
+				}
+				if signed < 0 {
+					signed = i
+				}
+			}
+			if signed < 0 {
+				return nil, []error{&ValidationError{Kind: KindInvalidSignature, Claim: "kid", Expected: kid,
+					Err: fmt.Errorf("%w, no signature with kid %v", ErrInvalidSignature, kid)}}
+			}
+			if len(errs[signed]) > 0 {
+				return tokens[signed], errs[signed]
+			}
+		}
+	}
+	if first < 0 {
+		return tokens[0], errs[0]
+	}
+	return tokens[first], nil
+}
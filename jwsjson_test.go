@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithJSONSerialization(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithJSONSerialization())
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	parts := strings.Split(testToken(t, key, nil, map[string]interface{}{"sub": "json", "exp": time.Now().Add(time.Hour).Unix()}), ".")
+	other, _ := testEd25519Key(t)
+	otherParts := strings.Split(testToken(t, other, nil, map[string]interface{}{"sub": "json", "exp": time.Now().Add(time.Hour).Unix()}), ".")
+
+	flattened := fmt.Sprintf(`{"payload":"%v","protected":"%v","header":{"kid":"ignored"},"signature":"%v"}`, parts[1], parts[0], parts[2])
+	general := fmt.Sprintf(`{"payload":"%v","signatures":[{"protected":"%v","signature":"%v"},{"protected":"%v","signature":"%v"}]}`,
+		parts[1], otherParts[0], otherParts[2], parts[0], parts[2])
+	for _, token := range []string{flattened, general, strings.Join(parts, ".")} {
+		verified, err := ver.ParseAndVerify(token)
+		if err != nil {
+			t.Fatalf("token parse fail, %v", err)
+		}
+		if verified.Claims.SUB != "json" {
+			t.Errorf("unexpected claims %+v", verified.Claims)
+		}
+	}
+
+	invalid := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"other key", fmt.Sprintf(`{"payload":"%v","signatures":[{"protected":"%v","signature":"%v"}]}`, parts[1], otherParts[0], otherParts[2]), ErrInvalidSignature},
+		{"no payload", fmt.Sprintf(`{"protected":"%v","signature":"%v"}`, parts[0], parts[2]), ErrMalformed},
+		{"no signature", fmt.Sprintf(`{"payload":"%v","signatures":[]}`, parts[1]), ErrMalformed},
+		{"both forms", fmt.Sprintf(`{"payload":"%v","protected":"%v","signature":"%v","signatures":[{"protected":"%v","signature":"%v"}]}`, parts[1], parts[0], parts[2], parts[0], parts[2]), ErrMalformed},
+		{"unprotected header only", fmt.Sprintf(`{"payload":"%v","header":{"alg":"EdDSA","kid":"test"},"signature":"%v"}`, parts[1], parts[2]), ErrMalformed},
+	}
+	for _, test := range invalid {
+		if _, err := ver.ParseAndVerify(test.token); !errors.Is(err, test.err) {
+			t.Errorf("%v: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	ver, err = NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(flattened); !errors.Is(err, ErrMalformed) {
+		t.Errorf("JSON serialization accepted without option, %v", err)
+	}
+}
+
+func TestSignaturePolicy(t *testing.T) {
+	keyA, _ := testEd25519Key(t)
+	keyB, _ := testEd25519Key(t)
+	unknown, _ := testEd25519Key(t)
+	claims := map[string]interface{}{"iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()}
+	var payload string
+	signature := func(key ed25519.PrivateKey, kid string) string {
+		parts := strings.Split(testToken(t, key, map[string]interface{}{"kid": kid}, claims), ".")
+		payload = parts[1]
+		return fmt.Sprintf(`{"protected":"%v","signature":"%v"}`, parts[0], parts[2])
+	}
+	general := func(signatures ...string) string {
+		return fmt.Sprintf(`{"payload":"%v","signatures":[%v]}`, payload, strings.Join(signatures, ","))
+	}
+	sigA, sigB, sigC := signature(keyA, "a"), signature(keyB, "b"), signature(unknown, "c")
+	keys := map[string]crypto.PublicKey{"a": keyA.Public(), "b": keyB.Public()}
+
+	tests := []struct {
+		name   string
+		policy SignaturePolicy
+		token  string
+		valid  bool
+	}{
+		{"any", AnySignature, general(sigC, sigA), true},
+		{"any none valid", AnySignature, general(sigC), false},
+		{"all", AllSignatures, general(sigA, sigB), true},
+		{"all with invalid", AllSignatures, general(sigA, sigC), false},
+		{"kid", SignaturesByKID("b"), general(sigC, sigB), true},
+		{"kids", SignaturesByKID("a", "b"), general(sigB, sigC, sigA), true},
+		{"kid missing", SignaturesByKID("a", "b"), general(sigA, sigC), false},
+		{"kid invalid", SignaturesByKID("c"), general(sigA, sigC), false},
+	}
+	for _, test := range tests {
+		ver, err := NewVerifierWithKeys(keys, testClientID, WithJSONSerialization(), WithSignaturePolicy(test.policy))
+		if err != nil {
+			t.Fatalf("New Verifier failed, %v", err)
+		}
+		_, err = ver.ParseAndVerify(test.token)
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidSignature) && !errors.Is(err, ErrKeyNotFound) {
+			t.Errorf("%v: expected invalid signature, got %v", test.name, err)
+		}
+	}
+}
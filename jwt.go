@@ -3,14 +3,18 @@ package jwt
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
-	"net/http"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,275 +25,909 @@ type Verifier struct {
 	keys     *keyCache
 	clientID string
 	issuer   string
+	// checkIssuer checks the issuer of a token instead of matching its iss claim to issuer if non-nil,
+	// e.g. for issuers templated by a claim
+	checkIssuer func(*JWT) error
+	// checkAudience checks the audience of a token instead of matching its aud claim to clientID if non-nil
+	checkAudience func(*JWT) error
+
+	cacheConfig cacheConfig
+	sharedKey   string
+	closeOnce   sync.Once
+
+	maxKeyAttempts int
+	// checks validate the claims of a verified token, as set by presets like NewFirebaseVerifier
+	checks []func(*JWT) error
+	// introspector checks that verified tokens are active if non-nil
+	introspector *Introspector
+	// extractor returns the token of a request for VerifyRequest, BearerToken if nil
+	extractor TokenExtractor
+	// debugErrors includes the raw token material in errors instead of its length
+	debugErrors bool
+	// errorFormatter maps the errors of ParseAndVerifyContext and VerifyRequest if non-nil
+	errorFormatter func(error) error
+	// auditHook is called with failed verifications if non-nil
+	auditHook func(context.Context, AuditEvent)
+	// onVerified and onRejected are called with verified tokens and failures if non-nil
+	onVerified func(*JWT)
+	onRejected func(error)
+	// stages record the duration of the verification stages
+	stages []StageRecorder
+	// results caches verified tokens if non-nil
+	results *resultCache
+	// lazyClaims only decodes the registered claims of tokens
+	lazyClaims bool
+	// clock returns the time tokens are verified at, time.Now if nil
+	clock func() time.Time
+	// failureLevel is the level failed verifications are logged at by cacheConfig.log
+	failureLevel logLevel
+	// healthGrace is the duration Healthy tolerates failed refreshes of expired keys
+	healthGrace time.Duration
+	// decryptionKeys decrypts encrypted tokens if non-nil
+	decryptionKeys DecryptionKeyFunc
+	// jsonSerialization accepts tokens in JWS JSON serialization
+	jsonSerialization bool
+	// signaturePolicy selects the signatures of tokens in general JWS JSON serialization which must be valid
+	signaturePolicy SignaturePolicy
+	// criticalHeaders are the extension header parameters understood by the application
+	criticalHeaders []string
+	// jku caches the keys of the allowed jku URLs of tokens if non-nil
+	jku *jkuCaches
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithIssuer sets the expected iss claim, the default is https://accounts.google.com.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithBackgroundRefresh starts a go routine which refreshes the keys the given duration before they expire,
+// so that verification doesn't have to wait for the keys to be fetched.
+// Close or Shutdown must be called to stop the go routine.
+func WithBackgroundRefresh(ahead time.Duration) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.background = true
+		v.cacheConfig.refreshAhead = ahead
+	}
+}
+
+// WithUnknownKeyRefresh sets the minimal interval between key refreshes triggered by tokens with an unknown kid,
+// the default is one minute. A non-positive interval disables the refresh, unknown keys then fail until the keys expire.
+func WithUnknownKeyRefresh(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.unknownKeyRefresh = interval
+	}
+}
+
+// WithKeyTTL clamps the duration the keys are cached, as reported by the KeyFetcher, to at least min and at most max.
+// A non-positive max doesn't limit the duration.
+func WithKeyTTL(min, max time.Duration) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.minTTL = min
+		v.cacheConfig.maxTTL = max
+	}
+}
+
+// WithRefreshJitter expires the keys up to jitter earlier, randomly, so that multiple servers don't refresh
+// the keys at the same time. The jitter is subtracted after applying WithKeyTTL.
+func WithRefreshJitter(jitter time.Duration) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.jitter = jitter
+	}
+}
+
+// WithCacheFile persists the keys and their expiration to the file at path whenever they are fetched,
+// a new Verifier loads them from the file instead of fetching them if they are not expired, e.g. for fast cold starts.
+func WithCacheFile(path string) Option {
+	return WithKeyCacheStore(fileCacheStore(path), "")
+}
+
+// WithKeyCacheStore stores the keys in store under key whenever they are fetched, key is typically the JWKS url.
+// Before fetching the keys, they are retrieved from store if they expire after the cached keys,
+// so that a fleet of servers sharing the store fetch the keys only once per expiration.
+func WithKeyCacheStore(store KeyCacheStore, key string) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.store = store
+		v.cacheConfig.storeKey = key
+	}
+}
+
+// WithX5CRoots only uses keys with an x5c certificate chain which is valid for roots,
+// e.g. for issuers which distribute their keys as certificates of an enterprise CA.
+func WithX5CRoots(roots *x509.CertPool) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.jwks.roots = roots
+	}
+}
+
+// WithMaxKeyAttempts sets the maximal number of keys a token without kid is verified with, the default is 5.
+// Such a token is verified with every cached key, it is rejected if there are more keys than max.
+// A non-positive max rejects tokens without kid.
+func WithMaxKeyAttempts(max int) Option {
+	return func(v *Verifier) {
+		v.maxKeyAttempts = max
+	}
+}
+
+// WithPinnedKeys only uses keys with one of the given RFC 7638 thumbprints, as returned by Thumbprint.
+func WithPinnedKeys(thumbprints ...string) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.pinned = make(map[string]bool, len(thumbprints))
+		for _, tp := range thumbprints {
+			v.cacheConfig.pinned[tp] = true
+		}
+	}
+}
+
+// WithJWKSLimits limits the size in bytes and the number of keys of a fetched key set, the defaults are 1 MiB and 100 keys.
+// A key set exceeding a limit fails with a *LimitError, a non-positive limit disables the limit.
+func WithJWKSLimits(maxSize int64, maxKeys int) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.jwks.maxSize = maxSize
+		v.cacheConfig.jwks.maxKeys = maxKeys
+	}
+}
+
+// WithDebugErrors includes the raw token, its parts and decoded header and claims in the errors of malformed tokens,
+// which are redacted to their lengths by default. Tokens are credentials, the errors must not be logged in production.
+func WithDebugErrors() Option {
+	return func(v *Verifier) {
+		v.debugErrors = true
+	}
+}
+
+// WithClock verifies the times of tokens, e.g. their expiration, at the time returned by clock instead of time.Now,
+// e.g. for deterministic tests or to replay logged tokens at their original time. The cached keys, with or without kid,
+// and signed key sets, see WithSignedJWKS, expire at clock too. WithUnknownKeyRefresh still limits refreshes in real time.
+func WithClock(clock func() time.Time) Option {
+	return func(v *Verifier) {
+		v.clock = clock
+		v.cacheConfig.clock = clock
+		v.cacheConfig.jwks.clock = clock
+	}
+}
+
+// now returns the time of the clock of the Verifier.
+func (v *Verifier) now() time.Time {
+	if v.clock != nil {
+		return v.clock()
+	}
+	return time.Now()
+}
+
+// WithLazyClaims only decodes the registered claims iss, sub, aud, exp, iat and nbf, which are verified, of the tokens.
+// The other fields of Claims are empty until JWT.DecodeClaims is called, e.g. for gateways which only need to know
+// whether a token is valid. The claims are fully decoded before the checks of presets like NewFirebaseVerifier.
+func WithLazyClaims() Option {
+	return func(v *Verifier) {
+		v.lazyClaims = true
+	}
+}
+
+// WithErrorFormatter maps the errors of ParseAndVerify and VerifyRequest with f, e.g. to the error types
+// or localized messages of an application. f is passed the original error, a *ValidationError, *KeyFetchError, etc.
+// and should return a non-nil error.
+func WithErrorFormatter(f func(err error) error) Option {
+	return func(v *Verifier) {
+		v.errorFormatter = f
+	}
+}
+
+// formatError returns err mapped by the error formatter, if any.
+func (v *Verifier) formatError(err error) error {
+	if v.errorFormatter == nil || err == nil {
+		return err
+	}
+	return v.errorFormatter(err)
+}
+
+// withChecks adds claim checks to a Verifier.
+func withChecks(checks ...func(*JWT) error) Option {
+	return func(v *Verifier) {
+		v.checks = append(v.checks, checks...)
+	}
+}
+
+// matchClaim returns an error unless the non-empty value of claim name matches one of the path.Match patterns.
+func matchClaim(name, value string, patterns []string) error {
+	if value != "" {
+		for _, pattern := range patterns {
+			if ok, err := path.Match(pattern, value); ok && err == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%v %q not allowed", name, value)
 }
 
 // NewVerifier returns a Verifier which parses and verifies Google issued tokens.
 // Tokens will be verified with keys supplied by keyFetcher and checked that their subject matches clientID.
-func NewVerifier(keyFetcher KeyFetcherFunc, clientID string) (*Verifier, error) {
-	c, err := newKeyCache(keyFetcher)
-	v := &Verifier{
-		keys:     c,
-		clientID: clientID,
-		issuer:   "https://accounts.google.com",
+func NewVerifier(keyFetcher KeyFetcherFunc, clientID string, opts ...Option) (*Verifier, error) {
+	return NewVerifierContext(context.Background(), keyFetcher, clientID, opts...)
+}
+
+// NewVerifierContext is like NewVerifier but accepts any KeyFetcher.
+// ctx is passed to keyFetcher for the initial key retrieval only.
+func NewVerifierContext(ctx context.Context, keyFetcher KeyFetcher, clientID string, opts ...Option) (*Verifier, error) {
+	return newFetchingVerifier(ctx, keyFetcher, "https://accounts.google.com", clientID, opts)
+}
+
+// newFetchingVerifier returns a Verifier with keys supplied by keyFetcher for tokens issued by issuer to audience.
+func newFetchingVerifier(ctx context.Context, keyFetcher KeyFetcher, issuer, audience string, opts []Option) (*Verifier, error) {
+	v := newVerifier(issuer, audience, opts)
+	if v.sharedKey != "" {
+		c, err := acquireSharedCache(ctx, v.sharedKey, keyFetcher, v.cacheConfig)
+		if err != nil {
+			v.sharedKey = "" // not acquired
+		}
+		v.keys = c
+		return v, err
 	}
+	c, err := newKeyCache(ctx, keyFetcher, v.cacheConfig)
+	v.keys = c
 	return v, err
+}
+
+// newVerifier returns a Verifier without key cache for tokens issued by issuer to audience,
+// with the default configuration overridden by opts.
+func newVerifier(issuer, audience string, opts []Option) *Verifier {
+	v := &Verifier{
+		clientID: audience,
+		issuer:   issuer,
+		cacheConfig: cacheConfig{
+			unknownKeyRefresh: time.Minute,
+			jwks:              defaultJWKSConfig,
+		},
+		maxKeyAttempts: 5,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
 
+// NewVerifierWithKeys returns a Verifier like NewVerifier, which verifies tokens with a static set of keys mapped by their key ID.
+// The keys are never refreshed. Keys may be parsed from a JSON Web Key Set with ParseJWKS,
+// only *rsa.PublicKey, ed25519.PublicKey and P-256 *ecdsa.PublicKey keys are supported.
+func NewVerifierWithKeys(keys map[string]crypto.PublicKey, clientID string, opts ...Option) (*Verifier, error) {
+	return newStaticVerifier(keys, "https://accounts.google.com", clientID, opts)
+}
+
+// newStaticVerifier returns a Verifier with a static key cache for tokens issued by issuer to audience.
+func newStaticVerifier(keys map[string]crypto.PublicKey, issuer, audience string, opts []Option) (*Verifier, error) {
+	v := newVerifier(issuer, audience, opts)
+	c, err := newStaticKeyCache(keys, v.cacheConfig)
+	if err != nil {
+		return nil, err
+	}
+	v.keys = c
+	return v, nil
+}
+
+// Close stops the background key refresh, if any, and waits for it to return.
+// A shared cache is stopped only when the last Verifier sharing it is closed.
+func (v *Verifier) Close() error {
+	return v.Shutdown(context.Background())
+}
+
+// Shutdown is like Close but returns ctx.Err() if ctx is done before the background refresh has returned.
+func (v *Verifier) Shutdown(ctx context.Context) error {
+	if v.sharedKey == "" {
+		return v.keys.shutdown(ctx)
+	}
+	var err error
+	v.closeOnce.Do(func() {
+		err = releaseSharedCache(ctx, v.sharedKey)
+	})
+	return err
 }
 
 // ParseAndVerify returns a Go representation of a Google issued tokenString.
 // A non-nil error implies that the token is invalid.
 func (v *Verifier) ParseAndVerify(tokenString string) (*JWT, error) {
-	//TODO If you specified a hd parameter value in the request, verify that the ID token has a hd claim that matches an accepted G Suite hosted domain.
+	return v.ParseAndVerifyContext(context.Background(), tokenString)
+}
 
-	parts := strings.Split(tokenString, ".")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("malformed token %v", tokenString)
+// ParseAndVerifyContext is like ParseAndVerify, ctx is passed to the KeyFetcher if the keys need to be refreshed.
+func (v *Verifier) ParseAndVerifyContext(ctx context.Context, tokenString string) (*JWT, error) {
+	var span Span
+	var fetched bool
+	start := time.Now()
+	if v.cacheConfig.tracer != nil {
+		ctx, span = v.cacheConfig.tracer.Start(ctx, "jwt.ParseAndVerify")
+	}
+	if v.cacheConfig.tracer != nil || v.cacheConfig.metrics != nil {
+		ctx = withFetchReport(ctx, &fetched)
+	}
+	var token *JWT
+	var errs []error
+	var hash [sha256.Size]byte
+	// introspected tokens aren't cached so that revoked tokens are rejected
+	results := v.results
+	if v.introspector != nil {
+		results = nil
+	}
+	if results != nil {
+		hash = sha256.Sum256([]byte(tokenString))
+		token = results.get(hash, v.now())
+	}
+	if token == nil {
+		token, errs = v.verify(ctx, tokenString, false, nil)
+		if len(errs) == 0 && results != nil {
+			results.add(hash, token)
+		}
+	}
+	var err error
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+	if span != nil {
+		endVerifySpan(span, token, fetched, err)
+	}
+	if v.cacheConfig.metrics != nil {
+		v.recordVerification(start, fetched, err)
 	}
-
-	parsedToken, err := parseJWT(parts[0], parts[1], parts[2])
 	if err != nil {
-		return nil, fmt.Errorf("decode token %v - %v", parts, err)
+		v.audit(ctx, token, err)
+		return nil, v.formatError(err)
 	}
+	if v.onVerified != nil {
+		v.onVerified(token)
+	}
+	return token, nil
+}
 
-	if parsedToken.Header.ALG != "RS256" {
-		return nil, fmt.Errorf("expected alg RS256, but token alg is %v", parsedToken.Header.ALG)
+// Inspect is like ParseAndVerifyContext but runs every check instead of stopping at the first failure,
+// e.g. to debug misconfigured clients. The error of an invalid token lists all failures as ValidationErrors,
+// the token is returned unless it's malformed, even if it's invalid.
+func (v *Verifier) Inspect(ctx context.Context, tokenString string) (*JWT, error) {
+	token, errs := v.verify(ctx, tokenString, true, nil)
+	if len(errs) > 0 {
+		return token, ValidationErrors(errs)
 	}
+	return token, nil
+}
 
-	key, err := v.keys.retrieveKey(parsedToken.Header.KID)
+// verify parses and verifies tokenString, and returns the failed checks, only the first one unless all is set.
+// A malformed token fails without further checks. Every check performed is added to report if non-nil.
+func (v *Verifier) verify(ctx context.Context, tokenString string, all bool, report *Report) (*JWT, []error) {
+	//TODO If you specified a hd parameter value in the request, verify that the ID token has a hd claim that matches an accepted G Suite hosted domain.
+
+	if v.decryptionKeys != nil && strings.Count(tokenString, ".") == 4 {
+		content, err := decryptJWE(ctx, tokenString, v.decryptionKeys)
+		if report != nil {
+			report.Checks = append(report.Checks, CheckResult{Name: "decrypt", Err: err})
+		}
+		if err != nil {
+			return nil, []error{err}
+		}
+		tokenString = content
+	}
+	if v.jsonSerialization && strings.HasPrefix(strings.TrimSpace(tokenString), "{") {
+		return v.verifyJSON(ctx, tokenString, all, report)
+	}
+
+	parsedToken, parts, err := splitJWT(tokenString, v.debugErrors, v.lazyClaims, v.stages)
+	if report != nil {
+		report.Token = parsedToken
+		report.Checks = append(report.Checks, CheckResult{Name: "parse", Err: err})
+	}
 	if err != nil {
-		return nil, fmt.Errorf("retrieve key - %v", err)
+		return nil, []error{err}
 	}
+	kid := parsedToken.Header.KID
 
-	if key == nil {
-		return nil, fmt.Errorf("matching key not found")
+	var errs []error
+	// check records the result of the check name and reports whether verification stops
+	check := func(name string, err error) bool {
+		if report != nil {
+			report.Checks = append(report.Checks, CheckResult{Name: name, Err: err})
+		}
+		if err == nil {
+			return false
+		}
+		errs = append(errs, err)
+		return !all
 	}
 
-	if err := verifySignature(strings.Join(parts[0:2], "."), parts[2], key); err != nil {
-		return nil, fmt.Errorf("verify signature - %v", err)
+	if parsedToken.Header.CRIT != nil && check("crit", v.checkCritical(parsedToken.Header.CRIT)) {
+		return parsedToken, errs
 	}
 
-	if parsedToken.Claims.ISS != v.issuer {
-		return nil, fmt.Errorf("invalid issuer")
+	// the signed header and claims are a prefix of tokenString
+	signed := tokenString[:len(parts[0])+1+len(parts[1])]
+	key, err := v.verifyTokenSignature(ctx, signed, parts[2], parsedToken)
+	if check("signature", err) {
+		return parsedToken, errs
+	}
+	if report != nil && key != nil {
+		report.KeyThumbprint, _ = Thumbprint(key)
 	}
 
-	if parsedToken.Claims.AUD != v.clientID {
-		return nil, fmt.Errorf("client ID does not match")
+	if check("iss", v.verifyIssuer(parsedToken)) {
+		return parsedToken, errs
 	}
 
-	if parsedToken.Claims.EXP <= time.Now().Unix() {
-		return nil, fmt.Errorf("token expired")
+	if check("aud", v.verifyAudience(parsedToken)) {
+		return parsedToken, errs
 	}
 
-	if parsedToken.Claims.IAT > time.Now().Unix() {
-		return nil, fmt.Errorf("token issued for future time")
+	verifiedAt := v.now()
+	now := verifiedAt.Unix()
+	err = nil
+	if parsedToken.Claims.EXP <= now {
+		err = &ValidationError{Kind: KindExpired, Claim: "exp", Expected: fmt.Sprintf("> %v", now), Actual: strconv.FormatInt(parsedToken.Claims.EXP, 10), KID: kid, Err: newExpiredError(parsedToken.Claims.EXP, verifiedAt)}
+	}
+	if check("exp", err) {
+		return parsedToken, errs
 	}
 
-	return parsedToken, nil
-}
+	err = nil
+	if parsedToken.Claims.IAT > now {
+		err = &ValidationError{Kind: KindIssuedInFuture, Claim: "iat", Expected: fmt.Sprintf("<= %v", now), Actual: strconv.FormatInt(parsedToken.Claims.IAT, 10), KID: kid,
+			Err: fmt.Errorf("token issued for future time")}
+	}
+	if check("iat", err) {
+		return parsedToken, errs
+	}
 
-func verifySignature(signedString, signature string, key *rsa.PublicKey) error {
-	sig, err := base64.RawURLEncoding.DecodeString(signature)
-	if err != nil {
-		return fmt.Errorf("unable to base64 decode signature %v, %v", signature, err)
+	err = nil
+	if parsedToken.Claims.NBF > now {
+		err = &ValidationError{Kind: KindNotYetValid, Claim: "nbf", Expected: fmt.Sprintf("<= %v", now), Actual: strconv.FormatInt(parsedToken.Claims.NBF, 10), KID: kid,
+			Err: fmt.Errorf("token not valid before %v", time.Unix(parsedToken.Claims.NBF, 0).UTC())}
+	}
+	if check("nbf", err) {
+		return parsedToken, errs
 	}
-	hashed := sha256.Sum256([]byte(signedString))
 
-	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], sig); err != nil {
-		return fmt.Errorf("signature verification failed, %v", err)
+	if len(v.checks) > 0 {
+		err := parsedToken.DecodeClaims()
+		if err != nil {
+			err = &ValidationError{Kind: KindMalformed, KID: kid, Err: wrapSentinel(ErrMalformed, ", decode claims - ", err)}
+		}
+		if check("parse", err) {
+			return parsedToken, errs
+		}
+	}
+	for _, c := range v.checks {
+		err := c(parsedToken)
+		if err != nil {
+			err = asValidationError(err, KindInvalidClaim, kid)
+		}
+		if check("claim", err) {
+			return parsedToken, errs
+		}
 	}
-	return nil
-}
 
-type JWT struct {
-	Header struct {
-		ALG string `json:"alg"`
-		KID string `json:"kid"`
-		TYP string `json:"typ"`
-	}
-	Claims struct {
-		ISS           string `json:"iss"`
-		AZP           string `json:"azp"`
-		AUD           string `json:"aud"`
-		SUB           string `json:"sub"`
-		Email         string `json:"email"`
-		EmailVerified bool   `json:"email_verified"`
-		ATHash        string `json:"at_hash"`
-		Name          string `json:"name"`
-		Picture       string `json:"picture"`
-		GivenName     string `json:"given_name"`
-		FamilyName    string `json:"family_name"`
-		Locale        string `json:"locale"`
-		Nonce         string `json:"nonce"`
-		Profile       string `json:"profile"`
-		HD            string `json:"hd"`
-		IAT           int64  `json:"iat"`
-		EXP           int64  `json:"exp"`
+	if v.introspector != nil {
+		_, err := v.introspector.introspect(ctx, tokenString, v.now())
+		if kind := kindOf(err); kind != 0 {
+			err = &ValidationError{Kind: kind, KID: kid, Err: err}
+		} else if err != nil {
+			err = &IntrospectionError{Err: err}
+		}
+		if check("active", err) {
+			return parsedToken, errs
+		}
 	}
-	Signature string
+
+	return parsedToken, errs
 }
 
-func parseJWT(header, claims, signature string) (*JWT, error) {
-	var token JWT
+// verifyTokenSignature verifies the signature of token over signedString, its encoded header and claims,
+// and returns the key which verified it.
+func (v *Verifier) verifyTokenSignature(ctx context.Context, signedString, signature string, token *JWT) (crypto.PublicKey, error) {
+	kid, alg := token.Header.KID, token.Header.ALG
+	switch alg {
+	case "RS256", "EdDSA", "ES256":
+	default:
+		return nil, &ValidationError{Kind: KindInvalidSignature, Claim: "alg", Expected: "RS256, EdDSA or ES256", Actual: alg, KID: kid,
+			Err: fmt.Errorf("%w, expected alg RS256, EdDSA or ES256, but token alg is %v", ErrInvalidSignature, alg)}
+	}
 
-	h, err := base64.RawURLEncoding.DecodeString(header)
-	if err != nil {
-		return nil, fmt.Errorf("unable to base64 decode %v, %v", header, err)
+	if v.jku != nil && token.Header.JKU != "" {
+		c, err := v.jku.cache(token.Header.JKU, v.cacheConfig)
+		if err != nil {
+			return nil, err
+		}
+		key, err := v.verifyWithKeys(ctx, c, signedString, signature, token)
+		if err == nil {
+			v.jku.add(token.Header.JKU, c)
+		}
+		return key, err
 	}
-	if err = json.Unmarshal(h, &token.Header); err != nil {
-		return nil, fmt.Errorf("unable to json decode %v, %v", h, err)
+	return v.verifyWithKeys(ctx, v.keys, signedString, signature, token)
+}
+
+// verifyWithKeys verifies the signature of token with a key of keys, and returns the key which verified it.
+func (v *Verifier) verifyWithKeys(ctx context.Context, keys *keyCache, signedString, signature string, token *JWT) (crypto.PublicKey, error) {
+	kid, alg := token.Header.KID, token.Header.ALG
+	if kid == "" && v.maxKeyAttempts > 0 {
+		key, err := v.verifyAnyKey(ctx, keys, signedString, signature, alg)
+		if kind := kindOf(err); kind != 0 {
+			return nil, &ValidationError{Kind: kind, Err: err}
+		}
+		return key, err
 	}
 
-	c, err := base64.RawURLEncoding.DecodeString(claims)
+	start := stageStart(v.stages)
+	key, err := keys.retrieveKey(ctx, kid)
+	recordStage(v.stages, "key_lookup", start)
 	if err != nil {
-		return nil, fmt.Errorf("unable to base64 decode %v, %v", claims, err)
+		return nil, &KeyFetchError{Err: err}
 	}
-	if err = json.Unmarshal(c, &token.Claims); err != nil {
-		return nil, fmt.Errorf("unable to json decode %v, %v", c, err)
+
+	if key.key == nil {
+		return nil, &ValidationError{Kind: KindKeyNotFound, Claim: "kid", Actual: kid, KID: kid, Err: ErrKeyNotFound}
 	}
-	token.Signature = signature
 
-	return &token, nil
-}
+	if key.alg != "" && key.alg != alg {
+		return nil, &ValidationError{Kind: KindInvalidSignature, Claim: "alg", Expected: key.alg, Actual: alg, KID: kid,
+			Err: fmt.Errorf("%w, token alg %v doesn't match key alg %v", ErrInvalidSignature, alg, key.alg)}
+	}
 
-// KeyFetcherFunc is used to retrieve the public keys. May be called asynchronously by multiple go routines.
-type KeyFetcherFunc func() (r io.ReadCloser, expires time.Time, err error)
+	start = stageStart(v.stages)
+	err = verifySignature(signedString, signature, alg, key.key)
+	recordStage(v.stages, "signature", start)
+	if err != nil {
+		return nil, &ValidationError{Kind: KindInvalidSignature, KID: kid, Err: wrapSentinel(ErrInvalidSignature, " - ", err)}
+	}
+	return key.key, nil
+}
 
-type keyCache struct {
-	keyFetcher KeyFetcherFunc
-	publicKeys map[string]*rsa.PublicKey
-	keyExpire  time.Time
-	mu         sync.RWMutex
+// verifyIssuer checks the issuer of token, a failure is a *ValidationError.
+func (v *Verifier) verifyIssuer(token *JWT) error {
+	if v.checkIssuer != nil {
+		err := v.checkIssuer(token)
+		var ve *ValidationError
+		if err == nil || errors.As(err, &ve) {
+			return err
+		}
+		return &ValidationError{Kind: KindInvalidIssuer, Claim: "iss", Actual: token.Claims.ISS, KID: token.Header.KID, Err: err}
+	}
+	if token.Claims.ISS != v.issuer {
+		return &ValidationError{Kind: KindInvalidIssuer, Claim: "iss", Expected: v.issuer, Actual: token.Claims.ISS, KID: token.Header.KID, Err: ErrInvalidIssuer}
+	}
+	return nil
 }
 
-func newKeyCache(keyFetcherFunc KeyFetcherFunc) (*keyCache, error) {
-	k := &keyCache{
-		keyFetcher: keyFetcherFunc,
+// verifyAudience checks the audience of token, a failure is a *ValidationError.
+func (v *Verifier) verifyAudience(token *JWT) error {
+	if v.checkAudience != nil {
+		if err := v.checkAudience(token); err != nil {
+			return asValidationError(err, KindInvalidAudience, token.Header.KID)
+		}
+		return nil
 	}
-	if _, err := k.retrieveKey(""); err != nil {
-		return k, err
+	if !token.Claims.AUD.Contains(v.clientID) {
+		return &ValidationError{Kind: KindInvalidAudience, Claim: "aud", Expected: v.clientID, Actual: strings.Join(token.Claims.AUD, " "), KID: token.Header.KID,
+			Err: fmt.Errorf("%w, client ID does not match", ErrInvalidAudience)}
 	}
-	return k, nil
+	return nil
 }
 
-// UpdatePublicKey sets the verifier public key to the key obtained from jwksReader.
-func (v *keyCache) UpdatePublicKey(jwksReader io.Reader, expiration time.Time) error {
-	m := make(map[string]*rsa.PublicKey)
-	jwks, err := parseJWKS(jwksReader)
+// verifyAnyKey verifies the signature of a token without kid with every key of cache matching alg,
+// up to maxKeyAttempts keys, and returns the key which verified it.
+func (v *Verifier) verifyAnyKey(ctx context.Context, cache *keyCache, signedString, signature, alg string) (crypto.PublicKey, error) {
+	start := stageStart(v.stages)
+	keys, err := cache.retrieveKeys(ctx)
+	recordStage(v.stages, "key_lookup", start)
+	if err != nil {
+		return nil, &KeyFetchError{Err: err}
+	}
+	start = stageStart(v.stages)
+	defer func() { recordStage(v.stages, "signature", start) }()
+	attempts := 0
+	for _, key := range keys {
+		if key.alg != "" && key.alg != alg {
+			continue
+		}
+		if attempts++; attempts > v.maxKeyAttempts {
+			return nil, fmt.Errorf("%w, token without kid, more than %v keys to verify", ErrKeyNotFound, v.maxKeyAttempts)
+		}
+		if err := verifySignature(signedString, signature, alg, key.key); err == nil {
+			return key.key, nil
+		}
+	}
+	if attempts == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return nil, fmt.Errorf("%w - no key matches token without kid", ErrInvalidSignature)
+}
 
+// maxPooledBuffer is the capacity above which buffers are not returned to bufferPool, so that large tokens aren't retained.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds *[]byte buffers for verifySignature.
+var bufferPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 2048)
+	return &b
+}}
+
+// verifySignature verifies an alg signature of signedString, key must be of the type used by alg.
+// The signed string and the decoded signature are copied to a pooled buffer rather than allocated.
+func verifySignature(signedString, signature, alg string, key crypto.PublicKey) error {
+	buf := bufferPool.Get().(*[]byte)
+	defer func() {
+		if cap(*buf) <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+	n := len(signedString) + len(signature) + base64.RawURLEncoding.DecodedLen(len(signature))
+	if cap(*buf) < n {
+		*buf = make([]byte, n)
+	}
+	b := (*buf)[:n]
+	signed := b[:copy(b, signedString)]
+	encoded := b[len(signed) : len(signed)+copy(b[len(signed):], signature)]
+	sig := b[len(signed)+len(encoded):]
+	m, err := base64.RawURLEncoding.Decode(sig, encoded)
 	if err != nil {
-		return fmt.Errorf("unable to parse JWKS %v", err)
+		return fmt.Errorf("unable to base64 decode signature of %v bytes, %w", len(signature), err)
 	}
+	sig = sig[:m]
 
-	for _, v := range jwks.Keys {
-		if v.E == "" || v.N == "" || v.KID == "" {
-			return fmt.Errorf("missing info in JWK %v", v)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			break
 		}
-		decodedN, err := base64.RawURLEncoding.DecodeString(v.N)
-		if err != nil {
-			return fmt.Errorf("unable to base64 decode jwk n value %v, %v", v.N, err)
+		hashed := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], sig); err != nil {
+			return fmt.Errorf("signature verification failed, %w", err)
 		}
-		decodedE, err := base64.RawURLEncoding.DecodeString(v.E)
-		if err != nil {
-			return fmt.Errorf("unable to base64 decode jwk e value %v, %v", v.E, err)
+		return nil
+	case ed25519.PublicKey:
+		if alg != "EdDSA" {
+			break
+		}
+		if !ed25519.Verify(k, signed, sig) {
+			return fmt.Errorf("signature verification failed, invalid Ed25519 signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			break
+		}
+		// the signature is the concatenation of the 32 byte r and s values
+		if len(sig) != 64 {
+			return fmt.Errorf("signature verification failed, invalid ES256 signature size %v", len(sig))
+		}
+		hashed := sha256.Sum256(signed)
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, hashed[:], r, s) {
+			return fmt.Errorf("signature verification failed, invalid ES256 signature")
 		}
+		return nil
+	}
+	return fmt.Errorf("key type %T doesn't match alg %v", key, alg)
+}
+
+// JWT is a parsed token.
+type JWT struct {
+	Header    Header
+	Claims    Claims
+	Signature string
+
+	rawHeader []byte
+	rawClaims []byte
+	// lazy is set if only the registered claims are decoded
+	lazy bool
+}
 
-		n := big.NewInt(0).SetBytes(decodedN)
-		e := big.NewInt(0).SetBytes(decodedE).Int64()
+// Header is the JOSE header of a token.
+type Header struct {
+	ALG string `json:"alg"`
+	KID string `json:"kid"`
+	TYP string `json:"typ"`
+	// JKU is the URL of the key set of the token, used with WithJKU.
+	JKU string `json:"jku,omitempty"`
+	// CRIT lists the extension header parameters which must be understood, see WithCriticalHeaders.
+	CRIT []string `json:"crit,omitempty"`
+}
 
-		m[v.KID] = &rsa.PublicKey{
-			N: n,
-			E: int(e),
+// Claims are the registered and Google ID token claims of a token, other claims are decoded with JWT.UnmarshalClaims.
+// Empty claims are omitted when Claims are encoded, e.g. by Signer.Sign.
+type Claims struct {
+	ISS           string   `json:"iss,omitempty"`
+	AZP           string   `json:"azp,omitempty"`
+	AUD           Audience `json:"aud,omitempty"`
+	SUB           string   `json:"sub,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified Bool     `json:"email_verified,omitempty"`
+	ATHash        string   `json:"at_hash,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Picture       string   `json:"picture,omitempty"`
+	GivenName     string   `json:"given_name,omitempty"`
+	FamilyName    string   `json:"family_name,omitempty"`
+	Locale        string   `json:"locale,omitempty"`
+	Nonce         string   `json:"nonce,omitempty"`
+	Profile       string   `json:"profile,omitempty"`
+	HD            string   `json:"hd,omitempty"`
+	AuthTime      int64    `json:"auth_time,omitempty"`
+	IAT           int64    `json:"iat,omitempty"`
+	EXP           int64    `json:"exp,omitempty"`
+	NBF           int64    `json:"nbf,omitempty"`
+}
+
+// Audience is the aud claim, a single audience or an array of audiences.
+type Audience []string
+
+// Contains reports whether aud is one of the audiences.
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
 		}
 	}
-	if len(m) == 0 {
-		return fmt.Errorf("no public keys %v", jwks)
+	return false
+}
+
+// UnmarshalJSON decodes a JSON string or array of strings.
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var auds []string
+	if err := json.Unmarshal(b, &auds); err != nil {
+		return fmt.Errorf("aud is neither a string nor an array of strings - %w", err)
 	}
+	*a = auds
+	return nil
+}
+
+// MarshalJSON encodes a single audience as a JSON string and multiple audiences as an array.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
 
-	v.mu.Lock()
-	v.publicKeys = m
-	v.keyExpire = expiration
-	v.mu.Unlock()
+// Bool is a boolean claim which issuers like Apple encode as a JSON boolean or as a "true" or "false" string.
+type Bool bool
+
+// UnmarshalJSON decodes a JSON boolean or a "true" or "false" string.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*b = true
+	case "false", `"false"`, "null":
+		*b = false
+	default:
+		return fmt.Errorf("%s is not a boolean", data)
+	}
 	return nil
 }
 
-// keyFetcher updates the key cache if it's expired and returns the requested key. If key is not in cache, nil is returned.
-func (v *keyCache) retrieveKey(kid string) (*rsa.PublicKey, error) {
-	v.mu.RLock()
-	if v.keyExpire.Before(time.Now()) {
-		v.mu.RUnlock() // UpdatePublicKey acquires mu.Lock
-		reader, expires, err := v.keyFetcher()
-		if err != nil {
-			return nil, fmt.Errorf("fetch key - %v", err)
+// UnmarshalHeader decodes the JSON header of the token into dst, e.g. for the extension parameters of WithCriticalHeaders.
+func (t *JWT) UnmarshalHeader(dst interface{}) error {
+	return json.Unmarshal(t.rawHeader, dst)
+}
+
+// UnmarshalClaims decodes the JSON claims of the token into dst, e.g. for claims which are not fields of Claims.
+func (t *JWT) UnmarshalClaims(dst interface{}) error {
+	return json.Unmarshal(t.rawClaims, dst)
+}
+
+// DecodeClaims decodes all fields of Claims of a token verified by a Verifier with WithLazyClaims,
+// it does nothing for other tokens. It must not be called concurrently for the same token.
+func (t *JWT) DecodeClaims() error {
+	if !t.lazy {
+		return nil
+	}
+	var c Claims
+	if err := unmarshalClaims(t.rawClaims, &c); err != nil {
+		return fmt.Errorf("unable to json decode claims, %w", err)
+	}
+	t.Claims, t.lazy = c, false
+	return nil
+}
+
+// splitJWT parses the compact serialization tokenString and returns its parts, a malformed token fails with a *ValidationError.
+// The raw token material is in the error only if debug is set.
+// Only the registered claims are decoded if lazy is set.
+func splitJWT(tokenString string, debug, lazy bool, stages []StageRecorder) (*JWT, []string, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		if debug {
+			return nil, nil, &ValidationError{Kind: KindMalformed, Err: fmt.Errorf("%w %v", ErrMalformed, tokenString)}
 		}
-		defer reader.Close()
-		if err = v.UpdatePublicKey(reader, expires); err != nil {
-			return nil, fmt.Errorf("update key cache - %v", err)
+		return nil, nil, &ValidationError{Kind: KindMalformed, Err: fmt.Errorf("%w of %v bytes with %v parts", ErrMalformed, len(tokenString), len(parts))}
+	}
+
+	token, err := parseJWT(parts[0], parts[1], parts[2], debug, lazy, stages)
+	if err != nil {
+		if debug {
+			err = wrapSentinel(ErrMalformed, fmt.Sprintf(", decode token %v - ", parts), err)
+		} else {
+			err = wrapSentinel(ErrMalformed, ", decode token - ", err)
 		}
-		v.mu.RLock()
+		return nil, nil, &ValidationError{Kind: KindMalformed, Err: err}
 	}
+	return token, parts, nil
+}
 
-	k := v.publicKeys[kid]
-	v.mu.RUnlock()
-	return k, nil
+// describe returns name with its raw value s for error messages if debug is set, or with its length otherwise.
+func describe(name, s string, debug bool) string {
+	if debug {
+		return fmt.Sprintf("%v %v", name, s)
+	}
+	return fmt.Sprintf("%v of %v bytes", name, len(s))
 }
 
-// DefaultKeyFetcher does an http request to obtain the google public certificates, the request times out after 10 seconds.
-// returns the response body and its max-age.
-func DefaultKeyFetcher() (r io.ReadCloser, expires time.Time, err error) {
-	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancelFunc()
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v3/certs", nil)
+// parseJWT decodes the header and claims of a token, only the registered claims if lazy is set.
+// The decode and unmarshal stages are recorded with stages.
+func parseJWT(header, claims, signature string, debug, lazy bool, stages []StageRecorder) (*JWT, error) {
+	var token JWT
+
+	start := stageStart(stages)
+	h, err := base64.RawURLEncoding.DecodeString(header)
 	if err != nil {
-		return nil, time.Now(), fmt.Errorf("create request - %v", err)
+		return nil, fmt.Errorf("unable to base64 decode %v, %w", describe("header", header, debug), err)
 	}
-	res, err := http.DefaultClient.Do(req)
+	decoding := stageSince(stages, start)
+	start = stageStart(stages)
+	if err = json.Unmarshal(h, &token.Header); err != nil {
+		return nil, fmt.Errorf("unable to json decode %v, %w", describe("header", string(h), debug), err)
+	}
+	unmarshaling := stageSince(stages, start)
 
+	start = stageStart(stages)
+	c, err := base64.RawURLEncoding.DecodeString(claims)
 	if err != nil {
-		return nil, time.Now(), fmt.Errorf("request - %v", err)
+		return nil, fmt.Errorf("unable to base64 decode %v of token with kid %q and alg %q, %w",
+			describe("claims", claims, debug), token.Header.KID, token.Header.ALG, err)
+	}
+	decoding += stageSince(stages, start)
+	start = stageStart(stages)
+	if lazy {
+		err = unmarshalRegisteredClaims(c, &token.Claims)
+	} else {
+		err = unmarshalClaims(c, &token.Claims)
 	}
-
-	age, err := extractMaxAge(res.Header.Get("cache-control"))
 	if err != nil {
-		return nil, time.Now(), fmt.Errorf("get max-age - %v", err)
+		return nil, fmt.Errorf("unable to json decode %v of token with kid %q and alg %q, %w",
+			describe("claims", string(c), debug), token.Header.KID, token.Header.ALG, err)
 	}
+	unmarshaling += stageSince(stages, start)
+	token.Signature = signature
+	token.rawHeader = h
+	token.rawClaims = c
+	token.lazy = lazy
 
-	return res.Body, time.Now().Add(time.Second * time.Duration(age)), nil
+	recordDuration(stages, "decode", decoding)
+	recordDuration(stages, "unmarshal", unmarshaling)
+	return &token, nil
 }
 
-// extractMaxAge returns the max-age value from an cache-control http response header or an error if finding a max-age failed.
-func extractMaxAge(cacheCtrlValue string) (int, error) {
-	cacheValues := strings.Split(cacheCtrlValue, ", ")
-	for _, v := range cacheValues {
-		if strings.HasPrefix(v, "max-age") {
-			maxAgeStr := strings.Split(v, "=")[1]
-			maxAge, err := strconv.Atoi(maxAgeStr)
-			if err != nil {
-				return 0, fmt.Errorf("convert max-age value %v to number - %v", maxAgeStr, err)
-			}
-			return maxAge, nil
-		}
-	}
-	return 0, fmt.Errorf("max-age not found in %v", cacheCtrlValue)
+// ErrNotModified may be returned by a KeyFetcher when the keys didn't change since its previous Fetch,
+// along with their new expiration. r must still return the keys, it is read only if they aren't cached yet.
+var ErrNotModified = errors.New("keys not modified")
+
+// KeyFetcher is used to retrieve the public keys. Fetch may be called asynchronously by multiple go routines.
+type KeyFetcher interface {
+	Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error)
 }
 
-type jwks struct {
-	Keys []struct {
-		// alg string
-		N   string `json:"n"`
-		E   string `json:"e"`
-		KID string `json:"kid"`
-		// kty string
-		// use string
-	} `json:"keys"`
+// KeyFetcherFunc is used to retrieve the public keys. May be called asynchronously by multiple go routines.
+type KeyFetcherFunc func() (r io.ReadCloser, expires time.Time, err error)
+
+// Fetch calls f, ctx is ignored.
+func (f KeyFetcherFunc) Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	return f()
 }
 
-func parseJWKS(r io.Reader) (*jwks, error) {
-	var keys jwks
-	if err := json.NewDecoder(r).Decode(&keys); err != nil {
-		return nil, fmt.Errorf("decode json %v - %v", r, err)
-	}
-	if keys.Keys == nil {
-		return nil, fmt.Errorf("empty key list %v", r)
-	}
-	return &keys, nil
+// KeyFetcherContextFunc is a KeyFetcherFunc which respects ctx cancellation.
+type KeyFetcherContextFunc func(ctx context.Context) (r io.ReadCloser, expires time.Time, err error)
+
+// Fetch calls f(ctx).
+func (f KeyFetcherContextFunc) Fetch(ctx context.Context) (r io.ReadCloser, expires time.Time, err error) {
+	return f(ctx)
 }
@@ -0,0 +1,19 @@
+module github.com/meblum/jwt/jwtgrpc/interceptor
+
+go 1.17
+
+require (
+	github.com/meblum/jwt v0.0.0
+	google.golang.org/grpc v1.56.3
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
+)
+
+replace github.com/meblum/jwt => ../..
@@ -0,0 +1,71 @@
+// Package interceptor provides gRPC server interceptors which verify the tokens of calls with github.com/meblum/jwt.
+//
+// It's a separate module so that github.com/meblum/jwt doesn't depend on google.golang.org/grpc:
+//
+//	srv := grpc.NewServer(
+//		grpc.UnaryInterceptor(interceptor.UnaryServerInterceptor(v)),
+//		grpc.StreamInterceptor(interceptor.StreamServerInterceptor(v)),
+//	)
+//
+// Handlers retrieve the verified token with jwthttp.TokenFromContext or jwthttp.ClaimsFromContext.
+package interceptor
+
+import (
+	"context"
+
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwtgrpc"
+	"github.com/meblum/jwt/jwthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a unary server interceptor which verifies the bearer token of the authorization metadata
+// of a call with v, as jwtgrpc.VerifyMetadata, and calls the handler with the verified token in its context.
+// A call without a valid token fails with codes.Unauthenticated, or codes.Unavailable if the error is retryable.
+func UnaryServerInterceptor(v jwthttp.TokenVerifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := verify(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor which verifies the token of a call as UnaryServerInterceptor,
+// the Context of the stream passed to the handler has the verified token.
+func StreamServerInterceptor(v jwthttp.TokenVerifier) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := verify(ss.Context(), v)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// verify returns a copy of ctx with the verified token of the incoming metadata of ctx, or a status error.
+func verify(ctx context.Context, v jwthttp.TokenVerifier) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, err := jwtgrpc.VerifyMetadata(ctx, v, md)
+	if jwt.IsRetryable(err) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return ctx, nil
+}
+
+// serverStream is a grpc.ServerStream with the context of the verified token.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,91 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type verifierFunc func(tokenString string) (*jwt.JWT, error)
+
+func (f verifierFunc) ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error) {
+	return f(tokenString)
+}
+
+var testVerifier = verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+	switch tokenString {
+	case "valid":
+		var token jwt.JWT
+		token.Claims.SUB = "1234"
+		return &token, nil
+	case "unavailable":
+		return nil, &jwt.KeyFetchError{Err: errors.New("keys unavailable")}
+	}
+	return nil, errors.New("invalid token")
+})
+
+func incoming(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	i := UnaryServerInterceptor(testVerifier)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, ok := jwthttp.ClaimsFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no claims")
+		}
+		return claims.SUB, nil
+	}
+	res, err := i(incoming("valid"), nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil || res != "1234" {
+		t.Errorf("unexpected response %v, %v", res, err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"invalid", incoming("invalid"), codes.Unauthenticated},
+		{"no metadata", context.Background(), codes.Unauthenticated},
+		{"retryable", incoming("unavailable"), codes.Unavailable},
+	}
+	for _, test := range tests {
+		if _, err := i(test.ctx, nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != test.code {
+			t.Errorf("%v: expected code %v, got %v", test.name, test.code, err)
+		}
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	i := StreamServerInterceptor(testVerifier)
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		if claims, ok := jwthttp.ClaimsFromContext(ss.Context()); !ok || claims.SUB != "1234" {
+			return errors.New("no claims")
+		}
+		return nil
+	}
+	if err := i(nil, &testStream{ctx: incoming("valid")}, &grpc.StreamServerInfo{}, handler); err != nil {
+		t.Errorf("stream fail, %v", err)
+	}
+	if err := i(nil, &testStream{ctx: incoming("invalid")}, &grpc.StreamServerInfo{}, handler); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Package jwtgrpc verifies the tokens of gRPC calls with github.com/meblum/jwt and forwards tokens with client calls.
+//
+// The package doesn't depend on google.golang.org/grpc. VerifyMetadata verifies the token of incoming metadata
+// and stores it for jwthttp.TokenFromContext and jwthttp.ClaimsFromContext, the server interceptors of the module
+// github.com/meblum/jwt/jwtgrpc/interceptor call it for every call.
+// PerRPCCredentials implements credentials.PerRPCCredentials for clients, e.g. with grpc.WithPerRPCCredentials.
+package jwtgrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+// VerifyMetadata verifies the bearer token of the authorization key of incoming metadata md, a metadata.MD,
+// and returns a copy of ctx with the verified token. The error wraps jwthttp.ErrMissingToken if md has no token.
+func VerifyMetadata(ctx context.Context, v jwthttp.TokenVerifier, md map[string][]string) (context.Context, error) {
+	values := md["authorization"]
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+		return nil, fmt.Errorf("%w - missing authorization metadata", jwthttp.ErrMissingToken)
+	}
+	scheme, token := "", strings.TrimSpace(values[0])
+	if i := strings.IndexByte(token, ' '); i >= 0 {
+		scheme, token = token[:i], strings.TrimSpace(token[i+1:])
+	}
+	if scheme != "" && !strings.EqualFold(scheme, "Bearer") {
+		return nil, fmt.Errorf("authorization scheme %v is not Bearer", scheme)
+	}
+	verified, err := v.ParseAndVerifyContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return jwthttp.NewContext(ctx, verified), nil
+}
+
+// PerRPCCredentials adds a bearer token to the authorization metadata of every call, implementing credentials.PerRPCCredentials.
+type PerRPCCredentials struct {
+	token    jwt.AccessTokenFunc
+	insecure bool
+}
+
+// NewPerRPCCredentials returns PerRPCCredentials forwarding the tokens, e.g. ID tokens, returned by token.
+// The credentials require transport security unless insecure is set, for local testing.
+func NewPerRPCCredentials(token jwt.AccessTokenFunc, insecure bool) *PerRPCCredentials {
+	return &PerRPCCredentials{token: token, insecure: insecure}
+}
+
+// GetRequestMetadata returns the authorization metadata of a call.
+func (c *PerRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get token - %w", err)
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity reports whether the credentials require transport security.
+func (c *PerRPCCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
@@ -0,0 +1,77 @@
+package jwtgrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+type verifierFunc func(tokenString string) (*jwt.JWT, error)
+
+func (f verifierFunc) ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error) {
+	return f(tokenString)
+}
+
+func TestVerifyMetadata(t *testing.T) {
+	v := verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+		if tokenString != "valid" {
+			return nil, errors.New("invalid token")
+		}
+		var token jwt.JWT
+		token.Claims.SUB = "1234"
+		return &token, nil
+	})
+
+	tests := []struct {
+		name  string
+		md    map[string][]string
+		valid bool
+	}{
+		{"bearer", map[string][]string{"authorization": {"Bearer valid"}}, true},
+		{"no scheme", map[string][]string{"authorization": {"valid"}}, true},
+		{"invalid", map[string][]string{"authorization": {"Bearer invalid"}}, false},
+		{"other scheme", map[string][]string{"authorization": {"Basic valid"}}, false},
+		{"no metadata", nil, false},
+	}
+	for _, test := range tests {
+		ctx, err := VerifyMetadata(context.Background(), v, test.md)
+		if test.valid {
+			if err != nil {
+				t.Errorf("%v: verification fail, %v", test.name, err)
+			} else if claims, ok := jwthttp.ClaimsFromContext(ctx); !ok || claims.SUB != "1234" {
+				t.Errorf("%v: unexpected claims in context %v", test.name, claims)
+			}
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid metadata not throwing error", test.name)
+		}
+	}
+
+	for _, md := range []map[string][]string{nil, {"authorization": {" "}}} {
+		if _, err := VerifyMetadata(context.Background(), v, md); !errors.Is(err, jwthttp.ErrMissingToken) {
+			t.Errorf("metadata %v, expected missing token, got %v", md, err)
+		}
+	}
+}
+
+func TestPerRPCCredentials(t *testing.T) {
+	c := NewPerRPCCredentials(func(ctx context.Context) (string, error) { return "token", nil }, false)
+	md, err := c.GetRequestMetadata(context.Background(), "https://api.example.com")
+	if err != nil || md["authorization"] != "Bearer token" {
+		t.Errorf("unexpected metadata %v, %v", md, err)
+	}
+	if !c.RequireTransportSecurity() {
+		t.Errorf("transport security not required")
+	}
+
+	c = NewPerRPCCredentials(func(ctx context.Context) (string, error) { return "", errors.New("no token") }, true)
+	if _, err := c.GetRequestMetadata(context.Background()); err == nil {
+		t.Errorf("token failure not throwing error")
+	}
+	if c.RequireTransportSecurity() {
+		t.Errorf("insecure credentials require transport security")
+	}
+}
@@ -0,0 +1,57 @@
+package jwthttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/meblum/jwt"
+)
+
+// RequireScope returns a middleware which only passes requests whose token, stored in the context by the Middleware,
+// has every one of scopes, as returned by jwt.JWT.Scopes. Other requests are responded 403 Forbidden
+// with an RFC 6750 insufficient_scope WWW-Authenticate header, requests without token 401 Unauthorized.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	challenge := fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, quotable(strings.Join(scopes, " ")))
+	return require(challenge, func(token *jwt.JWT) bool {
+		for _, scope := range scopes {
+			if !token.HasScope(scope) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// RequireRole returns a middleware which only passes requests whose token, stored in the context by the Middleware,
+// has one of roles, as returned by jwt.JWT.Roles. Other requests are responded 403 Forbidden
+// with an RFC 6750 insufficient_scope WWW-Authenticate header, requests without token 401 Unauthorized.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return require(`Bearer error="insufficient_scope"`, func(token *jwt.JWT) bool {
+		for _, role := range roles {
+			if token.HasRole(role) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// require returns a middleware which only passes requests whose token is allowed.
+func require(challenge string, allowed func(*jwt.JWT) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := TokenFromContext(r.Context())
+			if !ok {
+				Unauthorized(w, "", fmt.Errorf("%w - no verified token in context", ErrMissingToken))
+				return
+			}
+			if !allowed(token) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
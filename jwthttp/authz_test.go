@@ -0,0 +1,69 @@
+package jwthttp
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/meblum/jwt"
+)
+
+// signedToken returns a token with claims, and a Verifier of it.
+func signedToken(t *testing.T, claims map[string]interface{}) (*jwt.Verifier, string) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	v, err := jwt.NewVerifierWithKeys(map[string]crypto.PublicKey{"test": pub}, "client")
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	c := map[string]interface{}{"iss": "https://accounts.google.com", "aud": "client", "iat": time.Now().Unix(), "exp": time.Now().Add(time.Hour).Unix()}
+	for k, val := range claims {
+		c[k] = val
+	}
+	h, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": "test"})
+	b, _ := json.Marshal(c)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(b)
+	return v, signed + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, []byte(signed)))
+}
+
+func TestRequire(t *testing.T) {
+	v, token := signedToken(t, map[string]interface{}{"scope": "openid email", "roles": []string{"editor"}})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name      string
+		handler   http.Handler
+		status    int
+		challenge string
+	}{
+		{"scope", Middleware(v)(RequireScope("email")(ok)), http.StatusNoContent, ""},
+		{"scopes", Middleware(v)(RequireScope("openid", "email")(ok)), http.StatusNoContent, ""},
+		{"missing scope", Middleware(v)(RequireScope("email", "profile")(ok)), http.StatusForbidden, `Bearer error="insufficient_scope", scope="email profile"`},
+		{"role", Middleware(v)(RequireRole("admin", "editor")(ok)), http.StatusNoContent, ""},
+		{"missing role", Middleware(v)(RequireRole("admin")(ok)), http.StatusForbidden, `Bearer error="insufficient_scope"`},
+		{"no middleware", RequireScope("email")(ok), http.StatusUnauthorized, "Bearer"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		test.handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%v: unexpected status %v", test.name, w.Code)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != test.challenge {
+			t.Errorf("%v: unexpected WWW-Authenticate header %v", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package jwtecho provides echo middleware which verifies the bearer tokens of requests with github.com/meblum/jwt.
+//
+// It's a separate module so that github.com/meblum/jwt doesn't depend on echo:
+//
+//	e := echo.New()
+//	e.Use(jwtecho.Middleware(v, jwthttp.WithRealm("example")))
+//
+// Handlers retrieve the verified token with jwthttp.TokenFromContext(c.Request().Context()).
+package jwtecho
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+// Middleware returns an echo middleware which verifies the token of a request with v as jwthttp.Middleware,
+// all jwthttp options apply. Requests without a valid token are handled by the error handler.
+func Middleware(v jwthttp.TokenVerifier, opts ...jwthttp.Option) echo.MiddlewareFunc {
+	return echo.WrapMiddleware(jwthttp.Middleware(v, opts...))
+}
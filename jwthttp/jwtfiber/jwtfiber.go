@@ -0,0 +1,68 @@
+// Package jwtfiber provides fiber middleware which verifies the bearer tokens of requests with github.com/meblum/jwt.
+//
+// It's a separate module so that github.com/meblum/jwt doesn't depend on fiber:
+//
+//	app := fiber.New()
+//	app.Use(jwtfiber.Middleware(v, jwthttp.WithRealm("example")))
+//
+// Handlers retrieve the verified token with jwthttp.TokenFromContext(c.UserContext()).
+package jwtfiber
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+// Middleware returns a fiber middleware which verifies the token of a request with v as jwthttp.Middleware,
+// all jwthttp options apply. Requests without a valid token are handled by the error handler,
+// which responds through the fiber context.
+func Middleware(v jwthttp.TokenVerifier, opts ...jwthttp.Option) fiber.Handler {
+	m := jwthttp.Middleware(v, opts...)
+	return func(c *fiber.Ctx) error {
+		r, err := adaptor.ConvertRequest(c, false)
+		if err != nil {
+			return err
+		}
+		var verified *http.Request
+		m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified = r
+		})).ServeHTTP(&responseWriter{c: c, header: http.Header{}}, r.WithContext(c.UserContext()))
+		if verified == nil {
+			return nil
+		}
+		c.SetUserContext(verified.Context())
+		return c.Next()
+	}
+}
+
+// responseWriter is an http.ResponseWriter writing the response of a fiber context.
+type responseWriter struct {
+	c           *fiber.Ctx
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	for name, values := range w.header {
+		for _, value := range values {
+			w.c.Response().Header.Add(name, value)
+		}
+	}
+	w.c.Status(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.c.Write(b)
+}
@@ -0,0 +1,67 @@
+package jwtfiber
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+type verifierFunc func(tokenString string) (*jwt.JWT, error)
+
+func (f verifierFunc) ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error) {
+	return f(tokenString)
+}
+
+var testVerifier = verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+	if tokenString != "valid" {
+		return nil, errors.New("invalid token")
+	}
+	var token jwt.JWT
+	token.Claims.SUB = "1234"
+	return &token, nil
+})
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware(testVerifier, jwthttp.WithRealm("example")))
+	app.Get("/", func(c *fiber.Ctx) error {
+		claims, ok := jwthttp.ClaimsFromContext(c.UserContext())
+		if !ok || claims.SUB != "1234" {
+			t.Errorf("unexpected claims in context %v", claims)
+		}
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name      string
+		header    string
+		status    int
+		challenge string
+	}{
+		{"valid", "Bearer valid", http.StatusNoContent, ""},
+		{"invalid", "Bearer invalid", http.StatusUnauthorized, `Bearer realm="example", error="invalid_token", error_description="invalid token"`},
+		{"no header", "", http.StatusUnauthorized, `Bearer realm="example"`},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		res, err := app.Test(r)
+		if err != nil {
+			t.Fatalf("%v: request fail, %v", test.name, err)
+		}
+		if res.StatusCode != test.status {
+			t.Errorf("%v: unexpected status %v", test.name, res.StatusCode)
+		}
+		if got := res.Header.Get("WWW-Authenticate"); got != test.challenge {
+			t.Errorf("%v: unexpected WWW-Authenticate header %v", test.name, got)
+		}
+	}
+}
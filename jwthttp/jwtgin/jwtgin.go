@@ -0,0 +1,33 @@
+// Package jwtgin provides gin middleware which verifies the bearer tokens of requests with github.com/meblum/jwt.
+//
+// It's a separate module so that github.com/meblum/jwt doesn't depend on gin:
+//
+//	r := gin.New()
+//	r.Use(jwtgin.Middleware(v, jwthttp.WithRealm("example")))
+//
+// Handlers retrieve the verified token with jwthttp.TokenFromContext(c.Request.Context()).
+package jwtgin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+// Middleware returns a gin middleware which verifies the token of a request with v as jwthttp.Middleware,
+// all jwthttp options apply. Requests without a valid token are handled by the error handler and aborted.
+func Middleware(v jwthttp.TokenVerifier, opts ...jwthttp.Option) gin.HandlerFunc {
+	m := jwthttp.Middleware(v, opts...)
+	return func(c *gin.Context) {
+		verified := false
+		m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified = true
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+		if !verified {
+			c.Abort()
+		}
+	}
+}
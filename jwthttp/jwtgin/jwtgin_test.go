@@ -0,0 +1,66 @@
+package jwtgin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/meblum/jwt"
+	"github.com/meblum/jwt/jwthttp"
+)
+
+type verifierFunc func(tokenString string) (*jwt.JWT, error)
+
+func (f verifierFunc) ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error) {
+	return f(tokenString)
+}
+
+var testVerifier = verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+	if tokenString != "valid" {
+		return nil, errors.New("invalid token")
+	}
+	var token jwt.JWT
+	token.Claims.SUB = "1234"
+	return &token, nil
+})
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(testVerifier, jwthttp.WithRealm("example")))
+	router.GET("/", func(c *gin.Context) {
+		claims, ok := jwthttp.ClaimsFromContext(c.Request.Context())
+		if !ok || claims.SUB != "1234" {
+			t.Errorf("unexpected claims in context %v", claims)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name      string
+		header    string
+		status    int
+		challenge string
+	}{
+		{"valid", "Bearer valid", http.StatusNoContent, ""},
+		{"invalid", "Bearer invalid", http.StatusUnauthorized, `Bearer realm="example", error="invalid_token", error_description="invalid token"`},
+		{"no header", "", http.StatusUnauthorized, `Bearer realm="example"`},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%v: unexpected status %v", test.name, w.Code)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != test.challenge {
+			t.Errorf("%v: unexpected WWW-Authenticate header %v", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package jwthttp provides net/http middleware which verifies the bearer tokens of requests with github.com/meblum/jwt.
+//
+// The package doesn't depend on any router. Routers accepting net/http middleware use the Middleware directly,
+// the modules github.com/meblum/jwt/jwthttp/jwtgin, github.com/meblum/jwt/jwthttp/jwtecho and
+// github.com/meblum/jwt/jwthttp/jwtfiber adapt it to gin, echo and fiber. For other routers, Authenticate and Challenge
+// are the body of a middleware with their handler signature.
+package jwthttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/meblum/jwt"
+)
+
+// TokenVerifier verifies tokens, e.g. a *jwt.Verifier or *jwt.VerifierSet.
+type TokenVerifier interface {
+	ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error)
+}
+
+// Option configures the Middleware.
+type Option func(*config)
+
+// ErrMissingToken is wrapped by the errors passed to the error handler when a request has no token.
+var ErrMissingToken = errors.New("missing token")
+
+type config struct {
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	extractor    jwt.TokenExtractor
+	realm        string
+}
+
+// WithRealm sets the realm of the WWW-Authenticate header of the default error handler.
+func WithRealm(realm string) Option {
+	return func(c *config) {
+		c.realm = realm
+	}
+}
+
+// WithExtractor sets the TokenExtractor of the token of a request, the default is jwt.BearerExtractor.
+func WithExtractor(e jwt.TokenExtractor) Option {
+	return func(c *config) {
+		c.extractor = e
+	}
+}
+
+// WithCookie takes the token from the cookie name if it isn't in the Authorization header.
+func WithCookie(name string) Option {
+	return WithExtractor(jwt.ChainExtractors(jwt.BearerExtractor(), jwt.CookieExtractor(name)))
+}
+
+// WithErrorHandler sets the handler of requests without a valid token, err wraps ErrMissingToken if there is no token.
+// The default responds 503 Service Unavailable to retryable errors, as reported by jwt.IsRetryable,
+// and 401 Unauthorized with an RFC 6750 WWW-Authenticate header to others, as Unauthorized.
+func WithErrorHandler(h func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(c *config) {
+		c.errorHandler = h
+	}
+}
+
+// Middleware returns a middleware which verifies the token of a request, by default its bearer token, with v,
+// the verified token is stored in the request context for TokenFromContext and ClaimsFromContext.
+// Requests without a valid token are handled by the error handler.
+func Middleware(v TokenVerifier, opts ...Option) func(http.Handler) http.Handler {
+	c := config{extractor: jwt.BearerExtractor()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.errorHandler == nil {
+		realm := c.realm
+		c.errorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+			if jwt.IsRetryable(err) {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			Unauthorized(w, realm, err)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified, err := c.authenticate(r, v)
+			if err != nil {
+				c.errorHandler(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, verified)
+		})
+	}
+}
+
+// Authenticate verifies the token of r like the Middleware and returns a copy of r with the verified token in its context,
+// e.g. for routers with their own middleware signature. The error wraps ErrMissingToken if r has no token.
+// Options other than WithExtractor and WithCookie are ignored.
+func Authenticate(r *http.Request, v TokenVerifier, opts ...Option) (*http.Request, error) {
+	c := config{extractor: jwt.BearerExtractor()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c.authenticate(r, v)
+}
+
+// authenticate returns a copy of r with its token, as returned by the extractor, verified by v.
+func (c *config) authenticate(r *http.Request, v TokenVerifier) (*http.Request, error) {
+	tokenString, err := c.extractor.Extract(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w - %v", ErrMissingToken, err)
+	}
+	token, err := v.ParseAndVerifyContext(jwt.SourceIPContext(r), tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithContext(NewContext(r.Context(), token)), nil
+}
+
+// Unauthorized responds 401 Unauthorized with an RFC 6750 WWW-Authenticate header for err, as returned by Challenge.
+func Unauthorized(w http.ResponseWriter, realm string, err error) {
+	w.Header().Set("WWW-Authenticate", Challenge(realm, err))
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// Challenge returns the RFC 6750 WWW-Authenticate header value for err.
+// A missing token, err wrapping ErrMissingToken, only gets the Bearer challenge,
+// an invalid token the invalid_token error and a description of err.
+func Challenge(realm string, err error) string {
+	params := make([]string, 0, 3)
+	if realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", quotable(realm)))
+	}
+	if !errors.Is(err, ErrMissingToken) {
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", quotable(description(err))))
+	}
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+	return challenge
+}
+
+// maxDescription limits the length of an error_description.
+const maxDescription = 200
+
+// description returns the error_description of a verification error.
+func description(err error) string {
+	if err == nil {
+		return "the access token is invalid"
+	}
+	d := err.Error()
+	if len(d) > maxDescription {
+		d = d[:maxDescription]
+	}
+	return d
+}
+
+// quotable returns s without the characters which aren't allowed in RFC 6750 error_description and realm values,
+// or need escaping in a quoted string.
+func quotable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type tokenKey struct{}
+
+// NewContext returns a copy of ctx with the verified token.
+func NewContext(ctx context.Context, token *jwt.JWT) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// ClaimsFromContext returns the claims of the verified token stored in ctx by the Middleware, if any.
+func ClaimsFromContext(ctx context.Context) (*jwt.Claims, bool) {
+	token, ok := TokenFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return &token.Claims, true
+}
+
+// TokenFromContext returns the verified token stored in ctx by the Middleware, if any.
+func TokenFromContext(ctx context.Context) (*jwt.JWT, bool) {
+	token, ok := ctx.Value(tokenKey{}).(*jwt.JWT)
+	return token, ok
+}
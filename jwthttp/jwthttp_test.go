@@ -0,0 +1,147 @@
+package jwthttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/meblum/jwt"
+)
+
+// verifierFunc verifies tokens with a function.
+type verifierFunc func(tokenString string) (*jwt.JWT, error)
+
+func (f verifierFunc) ParseAndVerifyContext(ctx context.Context, tokenString string) (*jwt.JWT, error) {
+	return f(tokenString)
+}
+
+var testVerifier = verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+	if tokenString != "valid" {
+		return nil, errors.New("invalid token")
+	}
+	var token jwt.JWT
+	token.Claims.SUB = "1234"
+	return &token, nil
+})
+
+func TestMiddleware(t *testing.T) {
+	handler := Middleware(testVerifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := TokenFromContext(r.Context())
+		if !ok || token.Claims.SUB != "1234" {
+			t.Errorf("unexpected token in context %v", token)
+		}
+		if claims, ok := ClaimsFromContext(r.Context()); !ok || claims.SUB != "1234" {
+			t.Errorf("unexpected claims in context %v", claims)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name      string
+		header    string
+		status    int
+		challenge string
+	}{
+		{"valid", "Bearer valid", http.StatusNoContent, ""},
+		{"invalid", "Bearer invalid", http.StatusUnauthorized, `Bearer error="invalid_token", error_description="invalid token"`},
+		{"no header", "", http.StatusUnauthorized, "Bearer"},
+		{"basic auth", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "Bearer"},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%v: unexpected status %v", test.name, w.Code)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != test.challenge {
+			t.Errorf("%v: unexpected WWW-Authenticate header %v", test.name, got)
+		}
+	}
+
+	handler = Middleware(testVerifier, WithCookie("id_token"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "id_token", Value: "valid"})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("cookie token: unexpected status %v", w.Code)
+	}
+
+	var handled error
+	handler = Middleware(testVerifier, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusForbidden)
+	}))(http.NotFoundHandler())
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusForbidden || handled == nil {
+		t.Errorf("error handler not called, status %v", w.Code)
+	}
+	handler = Middleware(verifierFunc(func(tokenString string) (*jwt.JWT, error) {
+		return nil, &jwt.KeyFetchError{Err: errors.New("unavailable")}
+	}))(http.NotFoundHandler())
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer valid")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("key fetch failure: unexpected status %v", w.Code)
+	}
+
+	if _, ok := TokenFromContext(context.Background()); ok {
+		t.Errorf("token in empty context")
+	}
+	if _, ok := ClaimsFromContext(context.Background()); ok {
+		t.Errorf("claims in empty context")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	w := httptest.NewRecorder()
+	Unauthorized(w, `api "v1"`, errors.New("token expired\n\"at\" \u00e9poch"))
+	if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="api v1", error="invalid_token", error_description="token expiredat poch"` {
+		t.Errorf("unexpected WWW-Authenticate header %v", got)
+	}
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected status %v", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	Unauthorized(w, "api", fmt.Errorf("%w - no header", ErrMissingToken))
+	if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+		t.Errorf("unexpected WWW-Authenticate header %v", got)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer valid")
+	verified, err := Authenticate(r, testVerifier)
+	if err != nil {
+		t.Fatalf("verification fail, %v", err)
+	}
+	if claims, ok := ClaimsFromContext(verified.Context()); !ok || claims.SUB != "1234" {
+		t.Errorf("unexpected claims in context %v", claims)
+	}
+
+	r = httptest.NewRequest("GET", "/?token=valid", nil)
+	if _, err := Authenticate(r, testVerifier); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("missing token not wrapping ErrMissingToken, %v", err)
+	}
+	if _, err := Authenticate(r, testVerifier, WithExtractor(jwt.QueryExtractor("token"))); err != nil {
+		t.Errorf("query token: verification fail, %v", err)
+	}
+
+	if got := Challenge("api", errors.New("invalid token")); got != `Bearer realm="api", error="invalid_token", error_description="invalid token"` {
+		t.Errorf("unexpected challenge %v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package jwthttp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/meblum/jwt"
+)
+
+// Transport is an http.RoundTripper which authorizes every request with a bearer token, like the Transport of golang.org/x/oauth2,
+// e.g. with the ID tokens of jwt.NewServiceAccountIDTokenFunc or jwt.NewIAMIDTokenFunc, which are refreshed before they expire.
+// A golang.org/x/oauth2 TokenSource is adapted to the token function with
+//
+//	func(ctx context.Context) (string, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return "", err
+//		}
+//		return t.AccessToken, nil
+//	}
+//
+// and a token function to a TokenSource, e.g. for oauth2.Transport, by the module github.com/meblum/jwt/jwtoauth2.
+type Transport struct {
+	// Token returns the token of a request, called with the context of the request
+	Token jwt.AccessTokenFunc
+	// Base sends the requests, http.DefaultTransport if nil
+	Base http.RoundTripper
+}
+
+// NewClient returns an http.Client which authorizes every request with a bearer token returned by token.
+func NewClient(token jwt.AccessTokenFunc) *http.Client {
+	return &http.Client{Transport: &Transport{Token: token}}
+}
+
+// RoundTrip sends a copy of req with an authorization header.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("get token - %w", err)
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(r)
+}
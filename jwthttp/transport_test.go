@@ -0,0 +1,41 @@
+package jwthttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer id-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(func(context.Context) (string, error) { return "id-token", nil })
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("create request failed, %v", err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed, %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %v", res.StatusCode)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Errorf("request modified")
+	}
+
+	failing := errors.New("token failed")
+	client = NewClient(func(context.Context) (string, error) { return "", failing })
+	if _, err := client.Get(srv.URL); !errors.Is(err, failing) {
+		t.Errorf("expected token error, got %v", err)
+	}
+}
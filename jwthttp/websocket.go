@@ -0,0 +1,42 @@
+package jwthttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/meblum/jwt"
+)
+
+// SubprotocolPrefix prefixes the token in the Sec-WebSocket-Protocol subprotocol "bearer.<token>" of a WebSocket handshake.
+const SubprotocolPrefix = "bearer."
+
+// VerifyHandshake verifies the token of the WebSocket handshake r before upgrading the connection, and returns the verified token
+// whose claims hold for the lifetime of the connection. The token is taken from the subprotocol prefixed with SubprotocolPrefix,
+// or if param isn't empty, from the URL query parameter param. The error wraps ErrMissingToken if r has no token.
+func VerifyHandshake(r *http.Request, v TokenVerifier, param string) (*jwt.JWT, error) {
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, fmt.Errorf("not a WebSocket handshake")
+	}
+	extractor := jwt.SubprotocolExtractor(SubprotocolPrefix)
+	if param != "" {
+		extractor = jwt.ChainExtractors(extractor, jwt.QueryExtractor(param))
+	}
+	tokenString, err := extractor.Extract(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w - %v", ErrMissingToken, err)
+	}
+	return v.ParseAndVerifyContext(r.Context(), tokenString)
+}
+
+// headerContains reports whether a comma separated value of the header name is token, ignoring case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
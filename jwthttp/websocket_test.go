@@ -0,0 +1,47 @@
+package jwthttp
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifyHandshake(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		protocol string
+		upgrade  bool
+		valid    bool
+	}{
+		{"subprotocol", "/ws", "chat, bearer.valid", true, true},
+		{"query", "/ws?access_token=valid", "", true, true},
+		{"invalid", "/ws", "bearer.invalid", true, false},
+		{"no token", "/ws", "chat", true, false},
+		{"no upgrade", "/ws", "bearer.valid", false, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", test.target, nil)
+		if test.upgrade {
+			r.Header.Set("Connection", "keep-alive, Upgrade")
+			r.Header.Set("Upgrade", "websocket")
+		}
+		if test.protocol != "" {
+			r.Header.Set("Sec-WebSocket-Protocol", test.protocol)
+		}
+		token, err := VerifyHandshake(r, testVerifier, "access_token")
+		if test.valid && (err != nil || token.Claims.SUB != "1234") {
+			t.Errorf("%v: handshake verification fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid handshake not throwing error", test.name)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/ws?access_token=valid", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	if _, err := VerifyHandshake(r, testVerifier, ""); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("query token without param not wrapping ErrMissingToken, %v", err)
+	}
+}
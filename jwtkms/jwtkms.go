@@ -0,0 +1,202 @@
+// Package jwtkms signs tokens with Google Cloud KMS asymmetric signing keys, whose private keys never leave Cloud KMS.
+//
+// The package doesn't depend on the Cloud KMS client libraries, it calls the REST API with an access token,
+// e.g. returned by a golang.org/x/oauth2 TokenSource of Application Default Credentials:
+//
+//	ts, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/cloudkms")
+//	...
+//	signer, err := jwtkms.NewSigner(ctx, nil, func(ctx context.Context) (string, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return "", err
+//		}
+//		return t.AccessToken, nil
+//	}, "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1")
+package jwtkms
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/meblum/jwt"
+)
+
+// endpoint is the Cloud KMS REST API, a variable for tests.
+var endpoint = "https://cloudkms.googleapis.com/v1/"
+
+// algorithms maps the supported Cloud KMS signing algorithms to the JWS alg of their signatures.
+var algorithms = map[string]string{
+	"RSA_SIGN_PKCS1_2048_SHA256": "RS256",
+	"RSA_SIGN_PKCS1_3072_SHA256": "RS256",
+	"RSA_SIGN_PKCS1_4096_SHA256": "RS256",
+	"EC_SIGN_P256_SHA256":        "ES256",
+	"EC_SIGN_ED25519":            "EdDSA",
+}
+
+// Key is a Cloud KMS asymmetric signing key version implementing crypto.Signer.
+// The public key is fetched once by NewKey and cached, so that Public doesn't call Cloud KMS.
+type Key struct {
+	name      string
+	client    *http.Client
+	token     jwt.AccessTokenFunc
+	public    crypto.PublicKey
+	algorithm string
+}
+
+// NewKey returns the Cloud KMS key version name, of the form
+// projects/*/locations/*/keyRings/*/cryptoKeys/*/cryptoKeyVersions/*, fetching its public key.
+// Requests are sent with client, or http.DefaultClient if nil, and with the bearer token returned by token,
+// which may be nil if client authenticates requests.
+// Only key versions of the algorithms RSA_SIGN_PKCS1_{2048,3072,4096}_SHA256, EC_SIGN_P256_SHA256 and EC_SIGN_ED25519
+// are supported, their signatures are those of the JWS algs RS256, ES256 and EdDSA.
+func NewKey(ctx context.Context, client *http.Client, token jwt.AccessTokenFunc, name string) (*Key, error) {
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/cryptoKeyVersions/") {
+		return nil, fmt.Errorf("jwtkms: %q is not a key version name", name)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	k := &Key{name: name, client: client, token: token}
+	var res struct {
+		PEM       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := k.call(ctx, http.MethodGet, "/publicKey", nil, &res); err != nil {
+		return nil, fmt.Errorf("jwtkms: get public key - %w", err)
+	}
+	if _, ok := algorithms[res.Algorithm]; !ok {
+		return nil, fmt.Errorf("jwtkms: unsupported algorithm %v of %v", res.Algorithm, name)
+	}
+	block, _ := pem.Decode([]byte(res.PEM))
+	if block == nil {
+		return nil, fmt.Errorf("jwtkms: public key of %v is not PEM encoded", name)
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwtkms: parse public key - %w", err)
+	}
+	k.public, k.algorithm = public, res.Algorithm
+	return k, nil
+}
+
+// NewSigner returns a jwt.Signer signing with the Cloud KMS key version name, see NewKey.
+// The kid header of the tokens is the KID of the key.
+func NewSigner(ctx context.Context, client *http.Client, token jwt.AccessTokenFunc, name string) (*jwt.Signer, error) {
+	k, err := NewKey(ctx, client, token, name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := jwt.NewSigner(k, k.KID())
+	if err != nil {
+		return nil, fmt.Errorf("jwtkms: %w", err)
+	}
+	if alg := algorithms[k.algorithm]; s.Alg() != alg {
+		return nil, fmt.Errorf("jwtkms: %v key of %v signs %v tokens, expected %v", k.algorithm, name, s.Alg(), alg)
+	}
+	return s, nil
+}
+
+// KID returns the key id of the key version, its resource name, which is unique and changes with every rotated version.
+func (k *Key) KID() string {
+	return k.name
+}
+
+// Algorithm returns the Cloud KMS algorithm of the key version, e.g. EC_SIGN_P256_SHA256.
+func (k *Key) Algorithm() string {
+	return k.algorithm
+}
+
+// Public returns the cached public key of the key version, e.g. for jwt.NewVerifierWithKeys.
+func (k *Key) Public() crypto.PublicKey {
+	return k.public
+}
+
+// Sign signs digest with the key version, as SignContext with a background context.
+func (k *Key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return k.SignContext(context.Background(), digest, opts)
+}
+
+// SignContext signs digest, hashed with opts.HashFunc(), with the key version.
+// Ed25519 keys sign the message itself, passed as digest with a zero hash function.
+// ECDSA signatures are ASN.1 DER encoded, as those of *ecdsa.PrivateKey.
+func (k *Key) SignContext(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var req struct {
+		Digest map[string][]byte `json:"digest,omitempty"`
+		Data   []byte            `json:"data,omitempty"`
+	}
+	switch opts.HashFunc() {
+	case 0:
+		req.Data = digest
+	case crypto.SHA256:
+		req.Digest = map[string][]byte{"sha256": digest}
+	case crypto.SHA384:
+		req.Digest = map[string][]byte{"sha384": digest}
+	case crypto.SHA512:
+		req.Digest = map[string][]byte{"sha512": digest}
+	default:
+		return nil, fmt.Errorf("jwtkms: unsupported hash function %v", opts.HashFunc())
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("jwtkms: marshal request - %w", err)
+	}
+	var res struct {
+		Signature []byte `json:"signature"`
+		Name      string `json:"name"`
+	}
+	if err := k.call(ctx, http.MethodPost, ":asymmetricSign", body, &res); err != nil {
+		return nil, fmt.Errorf("jwtkms: sign - %w", err)
+	}
+	if res.Name != "" && res.Name != k.name {
+		return nil, fmt.Errorf("jwtkms: signed by %v, expected %v", res.Name, k.name)
+	}
+	return res.Signature, nil
+}
+
+// call sends a request with body to the method of the key version and decodes the JSON response into res.
+func (k *Key) call(ctx context.Context, method, suffix string, body []byte, res interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+k.name+suffix, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request - %w", err)
+	}
+	if body != nil {
+		req.Header.Set("content-type", "application/json")
+	}
+	if k.token != nil {
+		token, err := k.token(ctx)
+		if err != nil {
+			return fmt.Errorf("get access token - %w", err)
+		}
+		req.Header.Set("authorization", "Bearer "+token)
+	}
+	r, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request - %w", err)
+	}
+	defer r.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read body - %w", err)
+	}
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.Unmarshal(data, &e)
+		return fmt.Errorf("unexpected status %v from %v: %v", r.StatusCode, req.URL, e.Error.Message)
+	}
+	if err := json.Unmarshal(data, res); err != nil {
+		return fmt.Errorf("decode response - %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package jwtkms
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meblum/jwt"
+)
+
+// fakeKMS serves the publicKey and asymmetricSign methods of Cloud KMS key versions.
+// The algorithm of a key version is that of algorithms, or the SHA256 or Ed25519 algorithm of its key type.
+func fakeKMS(t *testing.T, keys map[string]crypto.Signer, algorithms map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("authorization") != "Bearer access" {
+			http.Error(w, `{"error":{"message":"unauthenticated"}}`, http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		switch {
+		case strings.HasSuffix(path, "/publicKey") && r.Method == http.MethodGet:
+			name := strings.TrimSuffix(path, "/publicKey")
+			key, ok := keys[name]
+			if !ok {
+				http.Error(w, `{"error":{"message":"not found"}}`, http.StatusNotFound)
+				return
+			}
+			der, err := x509.MarshalPKIXPublicKey(key.Public())
+			if err != nil {
+				t.Errorf("marshal public key failed, %v", err)
+			}
+			algorithm, ok := algorithms[name]
+			if !ok {
+				switch key.(type) {
+				case *rsa.PrivateKey:
+					algorithm = "RSA_SIGN_PKCS1_2048_SHA256"
+				case *ecdsa.PrivateKey:
+					algorithm = "EC_SIGN_P256_SHA256"
+				case ed25519.PrivateKey:
+					algorithm = "EC_SIGN_ED25519"
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"pem":       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				"algorithm": algorithm,
+			})
+		case strings.HasSuffix(path, ":asymmetricSign") && r.Method == http.MethodPost:
+			name := strings.TrimSuffix(path, ":asymmetricSign")
+			key := keys[name]
+			var req struct {
+				Digest map[string][]byte `json:"digest"`
+				Data   []byte            `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decode request failed, %v", err)
+			}
+			var sig []byte
+			var err error
+			if _, ok := key.(ed25519.PrivateKey); ok {
+				sig, err = key.Sign(rand.Reader, req.Data, crypto.Hash(0))
+			} else {
+				sig, err = key.Sign(rand.Reader, req.Digest["sha256"], crypto.SHA256)
+			}
+			if err != nil {
+				t.Errorf("sign failed, %v", err)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"signature": sig, "name": name})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	endpoint = srv.URL + "/"
+	t.Cleanup(func() { endpoint = "https://cloudkms.googleapis.com/v1/" })
+	return srv
+}
+
+func accessToken(context.Context) (string, error) {
+	return "access", nil
+}
+
+func TestSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	const prefix = "projects/p/locations/global/keyRings/r/cryptoKeys/"
+	keys := map[string]crypto.Signer{
+		prefix + "rsa/cryptoKeyVersions/1": rsaKey,
+		prefix + "ec/cryptoKeyVersions/1":  ecKey,
+		prefix + "ed/cryptoKeyVersions/1":  edKey,
+	}
+	fakeKMS(t, keys, nil)
+
+	for name := range keys {
+		s, err := NewSigner(context.Background(), nil, accessToken, name)
+		if err != nil {
+			t.Fatalf("New Signer failed, %v", err)
+		}
+		token, err := s.Sign(jwt.Claims{ISS: "https://auth.example.com", AUD: jwt.Audience{"api"}, EXP: time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("sign failed, %v", err)
+		}
+		v, err := jwt.NewVerifierWithKeys(map[string]crypto.PublicKey{name: s.Public()}, "api", jwt.WithIssuer("https://auth.example.com"))
+		if err != nil {
+			t.Fatalf("New Verifier failed, %v", err)
+		}
+		verified, err := v.ParseAndVerify(token)
+		if err != nil {
+			t.Fatalf("%v token parse fail, %v", s.Alg(), err)
+		}
+		if verified.Header.KID != name {
+			t.Errorf("expected kid %v, got %v", name, verified.Header.KID)
+		}
+	}
+
+	if _, err := NewKey(context.Background(), nil, accessToken, prefix+"missing/cryptoKeyVersions/1"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if _, err := NewKey(context.Background(), nil, nil, prefix+"rsa/cryptoKeyVersions/1"); err == nil {
+		t.Errorf("unauthenticated request not throwing error")
+	}
+	if _, err := NewKey(context.Background(), nil, accessToken, prefix+"rsa"); err == nil {
+		t.Errorf("key name without version not throwing error")
+	}
+
+	keys[prefix+"pss/cryptoKeyVersions/1"] = rsaKey
+	keys[prefix+"sha512/cryptoKeyVersions/1"] = rsaKey
+	fakeKMS(t, keys, map[string]string{
+		prefix + "pss/cryptoKeyVersions/1":    "RSA_SIGN_PSS_2048_SHA256",
+		prefix + "sha512/cryptoKeyVersions/1": "RSA_SIGN_PKCS1_4096_SHA512",
+	})
+	for _, name := range []string{prefix + "pss/cryptoKeyVersions/1", prefix + "sha512/cryptoKeyVersions/1"} {
+		if _, err := NewSigner(context.Background(), nil, accessToken, name); err == nil || !strings.Contains(err.Error(), "unsupported algorithm") {
+			t.Errorf("expected unsupported algorithm error for %v, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/meblum/jwt/jwtoauth2
+
+go 1.17
+
+require (
+	github.com/meblum/jwt v0.0.0
+	golang.org/x/oauth2 v0.21.0
+)
+
+replace github.com/meblum/jwt => ..
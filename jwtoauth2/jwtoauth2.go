@@ -0,0 +1,61 @@
+// Package jwtoauth2 adapts the token functions of github.com/meblum/jwt to golang.org/x/oauth2 TokenSources,
+// e.g. to authorize requests with the ID tokens of jwt.NewIAMIDTokenFunc through an oauth2.Transport:
+//
+//	f, err := jwt.NewIAMIDTokenFunc(nil, access, email, "https://api.example.com")
+//	...
+//	client := oauth2.NewClient(ctx, jwtoauth2.TokenSource(ctx, f))
+//
+// It's a separate module so that github.com/meblum/jwt doesn't depend on golang.org/x/oauth2.
+package jwtoauth2
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/meblum/jwt"
+	"golang.org/x/oauth2"
+)
+
+// TokenSource returns an oauth2.TokenSource of the tokens returned by f, called with ctx.
+// The Expiry of a token is its exp claim, so that the returned oauth2.ReuseTokenSource calls f again
+// shortly before it expires. Tokens which aren't JWTs, or have no exp claim, don't expire.
+func TokenSource(ctx context.Context, f jwt.AccessTokenFunc) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &tokenSource{ctx: ctx, f: f})
+}
+
+// tokenSource is an oauth2.TokenSource calling a token function for every token.
+type tokenSource struct {
+	ctx context.Context
+	f   jwt.AccessTokenFunc
+}
+
+// Token returns a new token of the token function.
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.f(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: t, TokenType: "Bearer", Expiry: expiry(t)}, nil
+}
+
+// expiry returns the time of the exp claim of the unverified token t, or the zero time if it has none.
+func expiry(t string) time.Time {
+	parts := strings.Split(t, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		EXP int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil || claims.EXP == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.EXP, 0)
+}
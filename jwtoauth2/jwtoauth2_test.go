@@ -0,0 +1,147 @@
+package jwtoauth2
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meblum/jwt"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenSource(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	s, err := jwt.NewSigner(key, "test")
+	if err != nil {
+		t.Fatalf("New Signer failed, %v", err)
+	}
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	calls := 0
+	ts := TokenSource(context.Background(), func(context.Context) (string, error) {
+		calls++
+		return s.Sign(jwt.Claims{SUB: "1234", EXP: exp.Unix()})
+	})
+	for i := 0; i < 2; i++ {
+		token, err := ts.Token()
+		if err != nil {
+			t.Fatalf("token fail, %v", err)
+		}
+		if !token.Expiry.Equal(exp) || token.TokenType != "Bearer" || !token.Valid() {
+			t.Errorf("unexpected token %+v", token)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the token to be reused, got %v calls", calls)
+	}
+
+	// an expired token is refreshed
+	exp = time.Now().Add(-time.Minute).Truncate(time.Second)
+	calls = 0
+	ts = TokenSource(context.Background(), func(context.Context) (string, error) {
+		calls++
+		return s.Sign(jwt.Claims{SUB: "1234", EXP: exp.Unix()})
+	})
+	ts.Token()
+	ts.Token()
+	if calls != 2 {
+		t.Errorf("expected expired tokens to be refreshed, got %v calls", calls)
+	}
+
+	ts = TokenSource(context.Background(), func(context.Context) (string, error) {
+		return "opaque", nil
+	})
+	if token, err := ts.Token(); err != nil || token.AccessToken != "opaque" || !token.Expiry.IsZero() {
+		t.Errorf("unexpected opaque token %+v, %v", token, err)
+	}
+
+	fail := errors.New("unavailable")
+	ts = TokenSource(context.Background(), func(context.Context) (string, error) {
+		return "", fail
+	})
+	if _, err := ts.Token(); !errors.Is(err, fail) {
+		t.Errorf("expected token function error, got %v", err)
+	}
+}
+
+// redirectTransport sends the requests of any host to the test server u.
+type redirectTransport struct {
+	u *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.URL.Scheme, r.URL.Host = t.u.Scheme, t.u.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestIAMIDTokenSource(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	s, err := jwt.NewSigner(key, "test")
+	if err != nil {
+		t.Fatalf("New Signer failed, %v", err)
+	}
+	const email = "invoker@my-project.iam.gserviceaccount.com"
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	var idToken string
+	generated := 0
+	iam := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/"+email+":generateIdToken") {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		generated++
+		token, err := s.Sign(jwt.Claims{ISS: "https://accounts.google.com", AUD: jwt.Audience{"https://api.example.com"}, EXP: exp.Unix()})
+		if err != nil {
+			t.Errorf("sign failed, %v", err)
+		}
+		idToken = token
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"token": idToken})
+	}))
+	defer iam.Close()
+	u, _ := url.Parse(iam.URL)
+	f, err := jwt.NewIAMIDTokenFunc(&http.Client{Transport: redirectTransport{u}}, nil, email, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("New ID token func failed, %v", err)
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+idToken {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+	ctx := context.Background()
+	ts := TokenSource(ctx, f)
+	client := oauth2.NewClient(ctx, ts)
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatalf("request fail, %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("request not authorized with the ID token, status %v", res.StatusCode)
+		}
+	}
+	if token, err := ts.Token(); err != nil || !token.Expiry.Equal(exp) {
+		t.Errorf("unexpected token %+v, %v", token, err)
+	}
+	if generated != 1 {
+		t.Errorf("expected one generated token, got %v", generated)
+	}
+}
@@ -0,0 +1,206 @@
+// Package jwtprom records the metrics of a github.com/meblum/jwt Verifier for Prometheus.
+//
+// The package doesn't depend on the Prometheus client library, a Recorder serves its metrics in the Prometheus
+// text exposition format:
+//
+//	rec := jwtprom.NewRecorder()
+//	v, err := jwt.NewVerifier(jwt.DefaultKeyFetcher, clientID, jwt.WithMetrics(rec))
+//	http.Handle("/metrics/jwt", rec)
+//
+// The metrics are jwt_verifications_total by result, "ok" or the jwt.ErrorCode of the failure,
+// jwt_verification_duration_seconds, jwt_key_fetches_total by result, "ok" or "error",
+// jwt_key_fetch_duration_seconds, jwt_key_cache_lookups_total by result, "hit" or "miss",
+// and jwt_verification_stage_duration_seconds by stage, as recorded by jwt.StageRecorder.
+package jwtprom
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the histogram buckets, those of the Prometheus client library.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// StageBuckets are the upper bounds in seconds of the histogram buckets of the verification stages,
+// which take microseconds unless the keys are fetched.
+var StageBuckets = []float64{.00001, .000025, .00005, .0001, .00025, .0005, .001, .0025, .005, .01, .1}
+
+// Recorder is a jwt.MetricsRecorder which serves the recorded metrics to Prometheus as an http.Handler.
+type Recorder struct {
+	mu            sync.Mutex
+	verifications map[string]uint64
+	verifyLatency *histogram
+	fetches       map[string]uint64
+	fetchLatency  *histogram
+	lookups       map[string]uint64
+	stages        map[string]*histogram
+}
+
+// NewRecorder returns a Recorder with histograms of DefaultBuckets, and StageBuckets for the stages.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		verifications: make(map[string]uint64),
+		verifyLatency: newHistogram(DefaultBuckets),
+		fetches:       make(map[string]uint64),
+		fetchLatency:  newHistogram(DefaultBuckets),
+		lookups:       make(map[string]uint64),
+		stages:        make(map[string]*histogram),
+	}
+}
+
+// Verification records a verification.
+func (r *Recorder) Verification(result string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifications[result]++
+	r.verifyLatency.observe(duration.Seconds())
+}
+
+// KeyFetch records a key fetch.
+func (r *Recorder) KeyFetch(duration time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches[result]++
+	r.fetchLatency.observe(duration.Seconds())
+}
+
+// CacheLookup records a key cache lookup.
+func (r *Recorder) CacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[result]++
+}
+
+// Stage records the duration of a verification stage.
+func (r *Recorder) Stage(stage string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.stages[stage]
+	if !ok {
+		h = newHistogram(StageBuckets)
+		r.stages[stage] = h
+	}
+	h.observe(duration.Seconds())
+}
+
+// ServeHTTP responds with the metrics in the Prometheus text exposition format.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w.
+func (r *Recorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cw := &countingWriter{w: w}
+	writeCounter(cw, "jwt_verifications_total", "Verifications by result.", r.verifications)
+	writeHistograms(cw, "jwt_verification_duration_seconds", "Duration of verifications.", "", map[string]*histogram{"": r.verifyLatency})
+	writeCounter(cw, "jwt_key_fetches_total", "Key fetches by result.", r.fetches)
+	writeHistograms(cw, "jwt_key_fetch_duration_seconds", "Duration of key fetches.", "", map[string]*histogram{"": r.fetchLatency})
+	writeCounter(cw, "jwt_key_cache_lookups_total", "Key cache lookups by result.", r.lookups)
+	if len(r.stages) > 0 {
+		writeHistograms(cw, "jwt_verification_stage_duration_seconds", "Duration of verification stages.", "stage", r.stages)
+	}
+	return cw.n, cw.err
+}
+
+// writeCounter writes a counter with a result label.
+func writeCounter(w io.Writer, name, help string, values map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v counter\n", name, help, name)
+	results := make([]string, 0, len(values))
+	for result := range values {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+	for _, result := range results {
+		fmt.Fprintf(w, "%v{result=%q} %v\n", name, result, values[result])
+	}
+}
+
+type histogram struct {
+	bounds []float64
+	counts []uint64 // the non-cumulative counts of the buckets
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.count++
+	h.sum += v
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// writeHistograms writes the histograms by the values of label, a single histogram without label if label is empty.
+func writeHistograms(w io.Writer, name, help, label string, histograms map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v histogram\n", name, help, name)
+	values := make([]string, 0, len(histograms))
+	for value := range histograms {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		var labels string
+		if label != "" {
+			labels = fmt.Sprintf("%v=%q", label, value)
+		}
+		histograms[value].write(w, name, labels)
+	}
+}
+
+// write writes the series of the histogram with labels, which are comma separated.
+func (h *histogram) write(w io.Writer, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%v_bucket{%v%vle=%q} %v\n", name, labels, sep, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%v_bucket{%v%vle=\"+Inf\"} %v\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%v_sum%v %v\n%v_count%v %v\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64), name, labels, h.count)
+}
+
+// countingWriter counts the bytes written to w and keeps the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,60 @@
+package jwtprom
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meblum/jwt"
+)
+
+var (
+	_ jwt.MetricsRecorder = (*Recorder)(nil)
+	_ jwt.StageRecorder   = (*Recorder)(nil)
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+	r.Verification("ok", 2*time.Millisecond)
+	r.Verification("token_expired", 20*time.Millisecond)
+	r.KeyFetch(100*time.Millisecond, nil)
+	r.KeyFetch(time.Second, errors.New("unavailable"))
+	r.CacheLookup(true)
+	r.CacheLookup(false)
+	r.CacheLookup(true)
+	r.Stage("signature", 40*time.Microsecond)
+	r.Stage("decode", 2*time.Microsecond)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %v", ct)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE jwt_verifications_total counter\n",
+		`jwt_verifications_total{result="ok"} 1`,
+		`jwt_verifications_total{result="token_expired"} 1`,
+		"# TYPE jwt_verification_duration_seconds histogram\n",
+		`jwt_verification_duration_seconds_bucket{le="0.005"} 1`,
+		`jwt_verification_duration_seconds_bucket{le="0.025"} 2`,
+		`jwt_verification_duration_seconds_bucket{le="+Inf"} 2`,
+		"jwt_verification_duration_seconds_count 2\n",
+		`jwt_key_fetches_total{result="error"} 1`,
+		`jwt_key_fetch_duration_seconds_bucket{le="0.1"} 1`,
+		"jwt_key_fetch_duration_seconds_sum 1.1\n",
+		`jwt_key_cache_lookups_total{result="hit"} 2`,
+		`jwt_key_cache_lookups_total{result="miss"} 1`,
+		"# TYPE jwt_verification_stage_duration_seconds histogram\n",
+		`jwt_verification_stage_duration_seconds_bucket{stage="decode",le="1e-05"} 1`,
+		`jwt_verification_stage_duration_seconds_bucket{stage="signature",le="2.5e-05"} 0`,
+		`jwt_verification_stage_duration_seconds_bucket{stage="signature",le="5e-05"} 1`,
+		`jwt_verification_stage_duration_seconds_count{stage="signature"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("%v not in metrics\n%v", want, body)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// KeycloakClaims are the Keycloak specific claims of a token, decoded with JWT.UnmarshalClaims.
+type KeycloakClaims struct {
+	PreferredUsername string `json:"preferred_username"`
+	RealmAccess       struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+	// ResourceAccess maps client IDs to the client roles of the user
+	ResourceAccess map[string]struct {
+		Roles []string `json:"roles"`
+	} `json:"resource_access"`
+}
+
+// HasRealmRole reports whether the user has the realm role.
+func (c KeycloakClaims) HasRealmRole(role string) bool {
+	return containsString(c.RealmAccess.Roles, role)
+}
+
+// HasClientRole reports whether the user has the role of clientID.
+func (c KeycloakClaims) HasClientRole(clientID, role string) bool {
+	return containsString(c.ResourceAccess[clientID].Roles, role)
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// keycloakIssuer returns the issuer of the tokens of a Keycloak realm.
+func keycloakIssuer(baseURL, realm string) string {
+	return fmt.Sprintf("%v/realms/%v", strings.TrimSuffix(baseURL, "/"), realm)
+}
+
+// NewKeycloakKeyFetcher returns an HTTPKeyFetcher which obtains the keys which sign the tokens of a Keycloak realm,
+// baseURL is the Keycloak server URL, e.g. https://keycloak.example.com or https://keycloak.example.com/auth for older versions.
+func NewKeycloakKeyFetcher(baseURL, realm string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(keycloakIssuer(baseURL, realm)+"/protocol/openid-connect/certs", opts...)
+}
+
+// NewKeycloakVerifier returns a Verifier which parses and verifies the tokens of a Keycloak realm issued to clientID.
+// Tokens are verified with the keys of NewKeycloakKeyFetcher and their issuer must be <baseURL>/realms/<realm>.
+// The roles of a user are decoded with KeycloakClaims.
+func NewKeycloakVerifier(baseURL, realm, clientID string, opts ...Option) (*Verifier, error) {
+	return newKeycloakVerifier(context.Background(), NewKeycloakKeyFetcher(baseURL, realm), baseURL, realm, clientID, opts)
+}
+
+func newKeycloakVerifier(ctx context.Context, keyFetcher KeyFetcher, baseURL, realm, clientID string, opts []Option) (*Verifier, error) {
+	if realm == "" {
+		return nil, fmt.Errorf("empty Keycloak realm")
+	}
+	return newFetchingVerifier(ctx, keyFetcher, keycloakIssuer(baseURL, realm), clientID, opts)
+}
@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+func TestKeycloakVerifier(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"OKP","crv":"Ed25519","kid":"test","x":"%v"}, {"kty":"RSA","use":"enc","kid":"enc","e":"AQAB","n":"AQAB"}]}`, base64.RawURLEncoding.EncodeToString(pub))
+	ver, err := newKeycloakVerifier(context.Background(), keyGetterFunc(jwks), "https://keycloak.example.com/", "my-realm", "my-app", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	token, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{
+		"iss":             "https://keycloak.example.com/realms/my-realm",
+		"aud":             "my-app",
+		"realm_access":    map[string]interface{}{"roles": []string{"admin"}},
+		"resource_access": map[string]interface{}{"my-app": map[string]interface{}{"roles": []string{"editor"}}},
+	}))
+	if err != nil {
+		t.Fatalf("token parse fail, %v", err)
+	}
+	var claims KeycloakClaims
+	if err := token.UnmarshalClaims(&claims); err != nil {
+		t.Fatalf("unmarshal claims failed, %v", err)
+	}
+	if !claims.HasRealmRole("admin") || claims.HasRealmRole("editor") {
+		t.Errorf("unexpected realm roles %v", claims.RealmAccess.Roles)
+	}
+	if !claims.HasClientRole("my-app", "editor") || claims.HasClientRole("other-app", "editor") {
+		t.Errorf("unexpected client roles %v", claims.ResourceAccess)
+	}
+
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"iss": "https://keycloak.example.com/realms/other", "aud": "my-app"})); err == nil {
+		t.Errorf("other realm not throwing error")
+	}
+}
@@ -0,0 +1,49 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+)
+
+// PublicKey returns the key with kid, or with RFC 7638 thumbprint kid, refreshing the keys as ParseAndVerify does.
+func (v *Verifier) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k, err := v.publicKey(ctx, kid)
+	return k.key, err
+}
+
+func (v *Verifier) publicKey(ctx context.Context, kid string) (verificationKey, error) {
+	k, err := v.keys.retrieveKey(ctx, kid)
+	if err != nil {
+		return verificationKey{}, &KeyFetchError{Err: err}
+	}
+	if k.key == nil {
+		return verificationKey{}, ErrKeyNotFound
+	}
+	return k, nil
+}
+
+// Keyfunc returns a callback returning the key of a token header, for parsers like github.com/golang-jwt/jwt
+// which use this package only as a managed key source:
+//
+//	keyfunc := verifier.Keyfunc(ctx)
+//	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) { return keyfunc(t.Header) })
+//
+// The header must have a kid, and the alg of the key, if declared by its JWK, must match the header alg.
+// The parser is responsible for checking the signature and claims.
+func (v *Verifier) Keyfunc(ctx context.Context) func(header map[string]interface{}) (interface{}, error) {
+	return func(header map[string]interface{}) (interface{}, error) {
+		kid, _ := header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("token header has no kid")
+		}
+		k, err := v.publicKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if alg, _ := header["alg"].(string); k.alg != "" && k.alg != alg {
+			return nil, fmt.Errorf("token alg %v doesn't match key alg %v", alg, k.alg)
+		}
+		return k.key, nil
+	}
+}
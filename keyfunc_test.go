@@ -0,0 +1,48 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"strings"
+	"testing"
+)
+
+func TestKeyfunc(t *testing.T) {
+	const kid = "f73e9e2b-242e-4842-8809-65ba74800972"
+	ver, err := NewVerifier(keyGetterFunc(strings.Replace(validKey, `"kty":"RSA"`, `"kty":"RSA","alg":"RS256"`, 1)), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	key, err := ver.PublicKey(context.Background(), kid)
+	if err != nil {
+		t.Fatalf("public key fail, %v", err)
+	}
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		t.Errorf("unexpected key type %T", key)
+	}
+	if _, err := ver.PublicKey(context.Background(), "unknown"); err == nil {
+		t.Errorf("unknown kid not throwing error")
+	}
+
+	keyfunc := ver.Keyfunc(context.Background())
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		valid  bool
+	}{
+		{"kid", map[string]interface{}{"kid": kid, "alg": "RS256"}, true},
+		{"alg mismatch", map[string]interface{}{"kid": kid, "alg": "HS256"}, false},
+		{"no kid", map[string]interface{}{"alg": "RS256"}, false},
+		{"unknown kid", map[string]interface{}{"kid": "unknown", "alg": "RS256"}, false},
+	}
+	for _, test := range tests {
+		k, err := keyfunc(test.header)
+		if test.valid && (err != nil || k == nil) {
+			t.Errorf("%v: keyfunc fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid header not throwing error", test.name)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KeyManager rotates the signing keys of an issuer: it signs with a current key, replaced by a new key every interval,
+// and publishes the keys with a JWKSHandler. The key following the current key is published an interval before it signs tokens,
+// so that verifiers caching the keys for less than interval know it, and a replaced key is published for at least grace,
+// which must exceed the lifetime of the tokens it signed. The kid of a key is its RFC 7638 thumbprint.
+// It may be used concurrently.
+type KeyManager struct {
+	generate func() (crypto.PrivateKey, error)
+	interval time.Duration
+	grace    time.Duration
+	handler  *JWKSHandler
+
+	// mu guards the signers
+	mu      sync.Mutex
+	current *Signer
+	next    *Signer
+	retired []retiredSigner
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// retiredSigner is a replaced Signer whose key is published until a time.
+type retiredSigner struct {
+	signer *Signer
+	until  time.Time
+}
+
+// NewKeyManager returns a KeyManager with keys returned by generate, rotated every interval in the background
+// until the KeyManager is closed. A failed rotation is retried after 10 seconds, signing with the current key meanwhile.
+// Its JWKSHandler has a Cache-Control max-age of a tenth of interval:
+//
+//	m, err := jwt.NewKeyManager(func() (crypto.PrivateKey, error) {
+//		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+//	}, 24*time.Hour, 2*time.Hour)
+//	...
+//	defer m.Close()
+//	http.Handle("/.well-known/jwks.json", m.Handler())
+func NewKeyManager(generate func() (crypto.PrivateKey, error), interval, grace time.Duration) (*KeyManager, error) {
+	if interval <= 0 || grace < 0 {
+		return nil, fmt.Errorf("invalid rotation interval %v or grace period %v", interval, grace)
+	}
+	m := &KeyManager{generate: generate, interval: interval, grace: grace}
+	var err error
+	if m.current, err = m.newSigner(); err != nil {
+		return nil, err
+	}
+	if m.next, err = m.newSigner(); err != nil {
+		return nil, err
+	}
+	if m.handler, err = NewJWKSHandler(interval/10, m.current, m.next); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.stop = cancel
+	m.done = make(chan struct{})
+	go m.rotateLoop(ctx)
+	return m, nil
+}
+
+// newSigner returns a Signer with a generated key and its thumbprint as kid.
+func (m *KeyManager) newSigner() (*Signer, error) {
+	key, err := m.generate()
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key - %w", err)
+	}
+	s, err := NewSigner(key, "")
+	if err != nil {
+		return nil, err
+	}
+	if s.kid, err = Thumbprint(s.public); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Signer returns the Signer of the current key.
+func (m *KeyManager) Signer() *Signer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// Sign signs claims with the current key, see Signer.Sign.
+func (m *KeyManager) Sign(claims interface{}) (string, error) {
+	return m.Signer().Sign(claims)
+}
+
+// Handler returns the JWKSHandler publishing the next, current and replaced keys.
+func (m *KeyManager) Handler() *JWKSHandler {
+	return m.handler
+}
+
+// Rotate replaces the current key by the next key, e.g. when the current key is compromised,
+// and generates a new next key. Replaced keys published for longer than grace are removed.
+func (m *KeyManager) Rotate() error {
+	next, err := m.newSigner()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	retired := []retiredSigner{{signer: m.current, until: now.Add(m.grace)}}
+	for _, r := range m.retired {
+		if r.until.After(now) {
+			retired = append(retired, r)
+		}
+	}
+	signers := []*Signer{next, m.next}
+	for _, r := range retired {
+		signers = append(signers, r.signer)
+	}
+	if err := m.handler.SetSigners(signers...); err != nil {
+		return err
+	}
+	m.current, m.next, m.retired = m.next, next, retired
+	return nil
+}
+
+// rotateLoop rotates the keys every interval until ctx is done.
+func (m *KeyManager) rotateLoop(ctx context.Context) {
+	defer close(m.done)
+	wait := m.interval
+	for {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		wait = m.interval
+		if err := m.Rotate(); err != nil && m.interval > minBackgroundRefresh {
+			wait = minBackgroundRefresh
+		}
+	}
+}
+
+// Close stops the background rotation and waits for it to return. The current key still signs tokens.
+func (m *KeyManager) Close() error {
+	m.stop()
+	<-m.done
+	return nil
+}
@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKeyManager(t *testing.T) {
+	generate := func() (crypto.PrivateKey, error) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	m, err := NewKeyManager(generate, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("New KeyManager failed, %v", err)
+	}
+	defer m.Close()
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	claims := Claims{ISS: "https://accounts.google.com", AUD: Audience{testClientID}, EXP: time.Now().Add(time.Hour).Unix()}
+	before, err := m.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign failed, %v", err)
+	}
+	// the verifier caches the keys published before the rotation
+	ver, err := NewVerifierContext(context.Background(), NewHTTPKeyFetcher(srv.URL), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	first := m.Signer()
+	if err := m.Rotate(); err != nil {
+		t.Fatalf("rotate failed, %v", err)
+	}
+	if m.Signer() == first {
+		t.Fatalf("key not rotated")
+	}
+	after, err := m.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign failed, %v", err)
+	}
+	for _, token := range []string{before, after} {
+		if _, err := ver.ParseAndVerify(token); err != nil {
+			t.Errorf("token parse fail, %v", err)
+		}
+	}
+
+	// without grace period the first key is removed at the next rotation
+	if err := m.Rotate(); err != nil {
+		t.Fatalf("rotate failed, %v", err)
+	}
+	ver, err = NewVerifierContext(context.Background(), NewHTTPKeyFetcher(srv.URL), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(before); err == nil {
+		t.Errorf("token of removed key not throwing error")
+	}
+	if _, err := ver.ParseAndVerify(after); err != nil {
+		t.Errorf("token of retired key parse fail, %v", err)
+	}
+
+	failing := errors.New("no entropy")
+	if _, err := NewKeyManager(func() (crypto.PrivateKey, error) { return nil, failing }, time.Hour, 0); !errors.Is(err, failing) {
+		t.Errorf("expected generate error, got %v", err)
+	}
+}
+
+func TestKeyManagerBackgroundRotation(t *testing.T) {
+	m, err := NewKeyManager(func() (crypto.PrivateKey, error) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}, time.Millisecond*10, time.Hour)
+	if err != nil {
+		t.Fatalf("New KeyManager failed, %v", err)
+	}
+	first := m.Signer()
+	time.Sleep(time.Millisecond * 50)
+	m.Close()
+	if m.Signer() == first {
+		t.Errorf("key not rotated in the background")
+	}
+}
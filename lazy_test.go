@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithLazyClaims(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithLazyClaims())
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	token, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"email_verified": 1}))
+	if err != nil {
+		t.Fatalf("token parse fail, %v", err)
+	}
+	if token.Claims.ISS != "https://accounts.google.com" || !token.Claims.AUD.Contains(testClientID) || token.Claims.SUB != "1234" ||
+		token.Claims.EXP == 0 || token.Claims.IAT == 0 || token.Claims.Email != "" {
+		t.Errorf("unexpected lazy claims %+v", token.Claims)
+	}
+	if err := token.DecodeClaims(); err == nil {
+		t.Errorf("invalid email_verified not throwing error")
+	}
+
+	token, err = ver.ParseAndVerify(testToken(t, key, nil, nil))
+	if err != nil {
+		t.Fatalf("token parse fail, %v", err)
+	}
+	if err := token.DecodeClaims(); err != nil || token.Claims.Email != "1234@gmail.com" || token.Claims.SUB != "1234" {
+		t.Errorf("unexpected decoded claims %+v, %v", token.Claims, err)
+	}
+
+	errNoEmail := errors.New("no email")
+	ver, err = NewVerifier(keyGetterFunc(jwks), testClientID, WithLazyClaims(), withChecks(func(token *JWT) error {
+		if token.Claims.Email == "" {
+			return errNoEmail
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("claims not decoded for checks, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"email_verified": 1})); !errors.Is(err, ErrMalformed) {
+		t.Errorf("invalid email_verified not throwing malformed error, %v", err)
+	}
+}
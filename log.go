@@ -0,0 +1,22 @@
+package jwt
+
+import "context"
+
+// logLevel is the level of a logged event, with the values of the slog levels.
+type logLevel int
+
+const (
+	levelDebug logLevel = -4
+	levelInfo  logLevel = 0
+	levelWarn  logLevel = 4
+)
+
+// logFunc logs an event with alternating attribute keys and values, as set by WithLogger.
+type logFunc func(ctx context.Context, level logLevel, msg string, args ...interface{})
+
+// log logs an event with f, if f isn't nil.
+func (f logFunc) log(ctx context.Context, level logLevel, msg string, args ...interface{}) {
+	if f != nil {
+		f(ctx, level, msg, args...)
+	}
+}
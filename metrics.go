@@ -0,0 +1,99 @@
+package jwt
+
+import "time"
+
+// MetricsRecorder records the metrics of a Verifier, e.g. the Recorder of the jwtprom package for Prometheus.
+// Its methods may be called concurrently.
+type MetricsRecorder interface {
+	// Verification records a verification by ParseAndVerify, result is "ok" or the ErrorCode of its failure.
+	Verification(result string, duration time.Duration)
+	// KeyFetch records a key fetch, err is its failure, if any.
+	KeyFetch(duration time.Duration, err error)
+	// CacheLookup records whether the keys of a verification were cached or had to be fetched.
+	CacheLookup(hit bool)
+}
+
+// StageRecorder may be implemented by a MetricsRecorder to record the duration of the stages of a verification:
+// "decode" for the base64 decoding of the token, "unmarshal" for the JSON decoding of its header and claims,
+// "key_lookup" for the retrieval of its key, including key fetches, and "signature" for the verification of its signature.
+type StageRecorder interface {
+	Stage(stage string, duration time.Duration)
+}
+
+// WithMetrics records the verifications, key fetches and key cache lookups of the Verifier with m,
+// in addition to the recorders of previous WithMetrics and WithExpvar options.
+// The stages of verifications are recorded too if m implements StageRecorder.
+func WithMetrics(m MetricsRecorder) Option {
+	return func(v *Verifier) {
+		if s, ok := m.(StageRecorder); ok {
+			v.stages = append(v.stages[:len(v.stages):len(v.stages)], s)
+		}
+		if r, ok := v.cacheConfig.metrics.(multiRecorder); ok {
+			v.cacheConfig.metrics = append(r[:len(r):len(r)], m)
+		} else if v.cacheConfig.metrics != nil {
+			v.cacheConfig.metrics = multiRecorder{v.cacheConfig.metrics, m}
+		} else {
+			v.cacheConfig.metrics = m
+		}
+	}
+}
+
+// multiRecorder records metrics with several recorders.
+type multiRecorder []MetricsRecorder
+
+func (m multiRecorder) Verification(result string, duration time.Duration) {
+	for _, r := range m {
+		r.Verification(result, duration)
+	}
+}
+
+func (m multiRecorder) KeyFetch(duration time.Duration, err error) {
+	for _, r := range m {
+		r.KeyFetch(duration, err)
+	}
+}
+
+func (m multiRecorder) CacheLookup(hit bool) {
+	for _, r := range m {
+		r.CacheLookup(hit)
+	}
+}
+
+// stageStart returns the start time of a stage, zero if no stages are recorded.
+func stageStart(stages []StageRecorder) time.Time {
+	if stages == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// stageSince returns the duration since start of a stage, zero if no stages are recorded.
+func stageSince(stages []StageRecorder, start time.Time) time.Duration {
+	if stages == nil {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// recordStage records the duration of stage, which started at start, if stages are recorded.
+func recordStage(stages []StageRecorder, stage string, start time.Time) {
+	recordDuration(stages, stage, stageSince(stages, start))
+}
+
+// recordDuration records the duration of stage if stages are recorded.
+func recordDuration(stages []StageRecorder, stage string, d time.Duration) {
+	for _, s := range stages {
+		s.Stage(stage, d)
+	}
+}
+
+// recordVerification records a verification which started at start, and whose keys were fetched if fetched is set.
+func (v *Verifier) recordVerification(start time.Time, fetched bool, err error) {
+	m := v.cacheConfig.metrics
+	result := "ok"
+	if err != nil {
+		result = ErrorCode(err)
+	}
+	m.Verification(result, time.Since(start))
+	m.CacheLookup(!fetched)
+}
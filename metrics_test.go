@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingRecorder counts the recorded metrics.
+type countingRecorder struct {
+	mu            sync.Mutex
+	verifications map[string]int
+	fetches       int
+	fetchErrors   int
+	hits, misses  int
+}
+
+func (r *countingRecorder) Verification(result string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifications[result]++
+}
+
+func (r *countingRecorder) KeyFetch(duration time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	if err != nil {
+		r.fetchErrors++
+	}
+}
+
+func (r *countingRecorder) CacheLookup(hit bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hit {
+		r.hits++
+	} else {
+		r.misses++
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	rec := &countingRecorder{verifications: make(map[string]int)}
+	expired := true
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		expires := time.Now().Add(time.Hour)
+		if expired {
+			expires = time.Now().Add(-time.Second)
+		}
+		return io.NopCloser(strings.NewReader(jwks)), expires, nil
+	}
+	ver, err := NewVerifier(fetcher, testClientID, WithMetrics(rec))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	expired = false
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})); err == nil {
+		t.Errorf("expired token not throwing error")
+	}
+
+	if rec.verifications["ok"] != 1 || rec.verifications["token_expired"] != 1 {
+		t.Errorf("unexpected verifications %v", rec.verifications)
+	}
+	if rec.fetches != 2 || rec.fetchErrors != 0 || rec.hits != 1 || rec.misses != 1 {
+		t.Errorf("unexpected fetches %v, errors %v, hits %v, misses %v", rec.fetches, rec.fetchErrors, rec.hits, rec.misses)
+	}
+}
+
+// stageRecorder is a countingRecorder which counts the recorded stages.
+type stageRecorder struct {
+	countingRecorder
+	stages map[string]int
+}
+
+func (r *stageRecorder) Stage(stage string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[stage]++
+}
+
+func TestWithMetricsStages(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	rec := &stageRecorder{countingRecorder: countingRecorder{verifications: make(map[string]int)}, stages: make(map[string]int)}
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithMetrics(rec))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, map[string]interface{}{"kid": nil}, nil)); err != nil {
+		t.Errorf("token without kid parse fail, %v", err)
+	}
+	for _, stage := range []string{"decode", "unmarshal", "key_lookup", "signature"} {
+		if rec.stages[stage] != 2 {
+			t.Errorf("unexpected %v stages %v", stage, rec.stages)
+		}
+	}
+}
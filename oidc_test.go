@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+func TestIDTokenVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"RSA","kid":"test","e":"AQAB","n":"%v"}]}`, base64.RawURLEncoding.EncodeToString(key.N.Bytes()))
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	sum := sha256.Sum256([]byte("access-token"))
+	atHash := base64.RawURLEncoding.EncodeToString(sum[:16])
+
+	idToken, err := NewIDTokenVerifier(ver).Verify(context.Background(), testToken(t, key, nil, map[string]interface{}{"nonce": "n", "at_hash": atHash, "hd": "example.com"}))
+	if err != nil {
+		t.Fatalf("token verification fail, %v", err)
+	}
+	if idToken.Issuer != "https://accounts.google.com" || idToken.Subject != "1234" || idToken.Nonce != "n" ||
+		len(idToken.Audience) != 1 || idToken.Audience[0] != testClientID || idToken.Expiry.IsZero() {
+		t.Errorf("unexpected id token %+v", idToken)
+	}
+	var claims struct {
+		HD string `json:"hd"`
+	}
+	if err := idToken.Claims(&claims); err != nil || claims.HD != "example.com" {
+		t.Errorf("unexpected claims %+v, %v", claims, err)
+	}
+	if err := idToken.VerifyAccessToken("access-token"); err != nil {
+		t.Errorf("access token verification fail, %v", err)
+	}
+	if err := idToken.VerifyAccessToken("other-token"); err == nil {
+		t.Errorf("invalid access token not throwing error")
+	}
+
+	if _, err := NewIDTokenVerifier(ver).Verify(context.Background(), "invalid"); err == nil {
+		t.Errorf("invalid token not throwing error")
+	}
+}
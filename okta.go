@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NewOktaVerifier returns a Verifier which parses and verifies the tokens of an Okta authorization server issued to audience,
+// the client ID for ID tokens or e.g. api://default for access tokens.
+// issuer is the org authorization server, e.g. https://example.okta.com, or a custom authorization server,
+// e.g. https://example.okta.com/oauth2/default. Its keys are fetched from the jwks_uri found by DiscoverKeyFetcher.
+// Access tokens of known clients are checked with WithOktaClientIDs.
+func NewOktaVerifier(issuer, audience string, opts ...Option) (*Verifier, error) {
+	ctx := context.Background()
+	keyFetcher, err := DiscoverKeyFetcher(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return newOktaVerifier(ctx, keyFetcher, issuer, audience, opts)
+}
+
+func newOktaVerifier(ctx context.Context, keyFetcher KeyFetcher, issuer, audience string, opts []Option) (*Verifier, error) {
+	return newFetchingVerifier(ctx, keyFetcher, strings.TrimSuffix(issuer, "/"), audience, opts)
+}
+
+// WithOktaClientIDs only accepts tokens whose cid claim, the client the access token was issued to, is one of clientIDs.
+func WithOktaClientIDs(clientIDs ...string) Option {
+	return withChecks(func(token *JWT) error {
+		var claims struct {
+			CID string `json:"cid"`
+		}
+		if err := token.UnmarshalClaims(&claims); err != nil {
+			return fmt.Errorf("unable to json decode claims, %w", err)
+		}
+		for _, id := range clientIDs {
+			if id == claims.CID {
+				return nil
+			}
+		}
+		return fmt.Errorf("client %v not allowed", claims.CID)
+	})
+}
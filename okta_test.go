@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOktaVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	const issuer = "https://example.okta.com/oauth2/default"
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"access token", nil, map[string]interface{}{"iss": issuer, "aud": "api://default", "cid": "client"}, true},
+		{"org server token", nil, map[string]interface{}{"iss": "https://example.okta.com", "aud": "api://default"}, false},
+		{"allowed client", []Option{WithOktaClientIDs("client")}, map[string]interface{}{"iss": issuer, "aud": "api://default", "cid": "client"}, true},
+		{"not allowed client", []Option{WithOktaClientIDs("client")}, map[string]interface{}{"iss": issuer, "aud": "api://default", "cid": "other"}, false},
+		{"no cid", []Option{WithOktaClientIDs("client")}, map[string]interface{}{"iss": issuer, "aud": "api://default"}, false},
+	}
+	for _, test := range tests {
+		ver, err := newOktaVerifier(context.Background(), keyGetterFunc(jwks), issuer+"/", "api://default", test.opts)
+		if err != nil {
+			t.Fatalf("%v: New Verifier failed, %v", test.name, err)
+		}
+		_, err = ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// NewVerifierFromPEM returns a Verifier like NewVerifierWithKeys, with the keys parsed by ParsePEM,
+// for tokens issued by issuer to audience.
+func NewVerifierFromPEM(pemBytes []byte, issuer, audience string, opts ...Option) (*Verifier, error) {
+	keys, err := ParsePEM(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return newStaticVerifier(keys, issuer, audience, opts)
+}
+
+// ParsePEM returns the public keys of PEM encoded PKIX or PKCS #1 public keys and X.509 certificates.
+// The key ID is taken from a kid PEM header if present, otherwise it is the hex encoded subject key ID of a certificate,
+// or the RFC 7638 thumbprint of the key.
+func ParsePEM(pemBytes []byte) (map[string]crypto.PublicKey, error) {
+	m := make(map[string]crypto.PublicKey)
+	for {
+		var block *pem.Block
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+
+		var key crypto.PublicKey
+		var kid string
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				key = cert.PublicKey
+				kid = hex.EncodeToString(cert.SubjectKeyId)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse PEM block %v, %w", block.Type, err)
+		}
+
+		if h := block.Headers["kid"]; h != "" {
+			kid = h
+		}
+		if kid == "" {
+			if kid, err = Thumbprint(key); err != nil {
+				return nil, err
+			}
+		}
+		m[kid] = key
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no public keys in PEM")
+	}
+	return m, nil
+}
+
+// Thumbprint returns the RFC 7638 JWK thumbprint of an *rsa.PublicKey, ed25519.PublicKey or P-256 *ecdsa.PublicKey key,
+// e.g. to pin keys with WithPinnedKeys.
+func Thumbprint(key crypto.PublicKey) (string, error) {
+	var jwk string
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		n := base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk = fmt.Sprintf(`{"e":"%v","kty":"RSA","n":"%v"}`, e, n)
+	case ed25519.PublicKey:
+		jwk = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%v"}`, base64.RawURLEncoding.EncodeToString(k))
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported curve %v", k.Curve.Params().Name)
+		}
+		x := base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32)))
+		y := base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32)))
+		jwk = fmt.Sprintf(`{"crv":"P-256","kty":"EC","x":"%v","y":"%v"}`, x, y)
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	sum := sha256.Sum256([]byte(jwk))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewVerifierFromPEM(t *testing.T) {
+	keys, err := ParseJWKS(strings.NewReader(validKey))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(keys["f73e9e2b-242e-4842-8809-65ba74800972"])
+	if err != nil {
+		t.Fatalf("marshal key failed, %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:    "PUBLIC KEY",
+		Headers: map[string]string{"kid": "f73e9e2b-242e-4842-8809-65ba74800972"},
+		Bytes:   der,
+	})
+
+	ver, err := NewVerifierFromPEM(pemBytes, "https://accounts.google.com", testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	if _, err := NewVerifierFromPEM([]byte("not pem"), "https://accounts.google.com", testClientID); err == nil {
+		t.Errorf("invalid PEM not throwing error")
+	}
+}
+
+func TestParsePEMCertificate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		SubjectKeyId: []byte{1, 2, 3},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate failed, %v", err)
+	}
+
+	keys, err := ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if err != nil {
+		t.Fatalf("parse PEM failed, %v", err)
+	}
+	if k, ok := keys["010203"].(*rsa.PublicKey); !ok || !k.Equal(&key.PublicKey) {
+		t.Errorf("expected certificate key with ID 010203, got %v", keys)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638 section 3.1
+	jwk := `{"keys": [{"kty":"RSA","kid":"2011-04-29","e":"AQAB","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"}]}`
+	keys, err := ParseJWKS(strings.NewReader(jwk))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	tp, err := Thumbprint(keys["2011-04-29"])
+	if expected := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; tp != expected || err != nil {
+		t.Errorf("expected thumbprint %v, got %v, %v", expected, tp, err)
+	}
+}
+
+func TestThumbprintLookupAndPinning(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	tp, err := Thumbprint(pub)
+	if err != nil {
+		t.Fatalf("thumbprint failed, %v", err)
+	}
+	keys := map[string]crypto.PublicKey{"kid": pub}
+
+	ver, err := NewVerifierWithKeys(keys, testClientID, WithPinnedKeys(tp))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, priv, map[string]interface{}{"kid": tp}, nil)); err != nil {
+		t.Errorf("token with thumbprint kid fail, %v", err)
+	}
+
+	if _, err := NewVerifierWithKeys(keys, testClientID, WithPinnedKeys("other")); err == nil {
+		t.Errorf("no pinned keys not throwing error")
+	}
+}
@@ -0,0 +1,32 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// NewPushVerifier returns a Verifier which parses and verifies the Google issued OIDC tokens of Pub/Sub push subscriptions
+// and Cloud Tasks HTTP targets issued to audience, by default the push endpoint URL.
+// The email claim of tokens must be serviceAccount, the service account the push is authenticated as, and verified.
+func NewPushVerifier(audience, serviceAccount string, opts ...Option) (*Verifier, error) {
+	return newPushVerifier(context.Background(), defaultKeyFetcher, audience, serviceAccount, opts)
+}
+
+func newPushVerifier(ctx context.Context, keyFetcher KeyFetcher, audience, serviceAccount string, opts []Option) (*Verifier, error) {
+	if serviceAccount == "" {
+		return nil, fmt.Errorf("empty push service account")
+	}
+	opts = append([]Option{withChecks(func(token *JWT) error {
+		if token.Claims.Email != serviceAccount || !token.Claims.EmailVerified {
+			return fmt.Errorf("email %v does not match push service account", token.Claims.Email)
+		}
+		return nil
+	})}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "https://accounts.google.com", audience, opts)
+}
+
+// VerifyPushRequest verifies the bearer token of a push request r with a Verifier returned by NewPushVerifier.
+func VerifyPushRequest(v *Verifier, r *http.Request) (*JWT, error) {
+	return v.VerifyRequest(r)
+}
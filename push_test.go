@@ -0,0 +1,43 @@
+package jwt
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerifyPushRequest(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	const email = "push@my-project.iam.gserviceaccount.com"
+	ver, err := newPushVerifier(context.Background(), keyGetterFunc(jwks), "https://example.com/push", email, nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+	}{
+		{"push", "Bearer " + testToken(t, key, nil, map[string]interface{}{"aud": "https://example.com/push", "email": email, "email_verified": true}), true},
+		{"lowercase scheme", "bearer " + testToken(t, key, nil, map[string]interface{}{"aud": "https://example.com/push", "email": email, "email_verified": true}), true},
+		{"other service account", "Bearer " + testToken(t, key, nil, map[string]interface{}{"aud": "https://example.com/push", "email": "other@example.com", "email_verified": true}), false},
+		{"unverified email", "Bearer " + testToken(t, key, nil, map[string]interface{}{"aud": "https://example.com/push", "email": email}), false},
+		{"other audience", "Bearer " + testToken(t, key, nil, map[string]interface{}{"aud": "https://example.com/other", "email": email, "email_verified": true}), false},
+		{"basic auth", "Basic dXNlcjpwYXNz", false},
+		{"no header", "", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/push", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		_, err := VerifyPushRequest(ver, r)
+		if test.valid && err != nil {
+			t.Errorf("%v: request verification fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid request not throwing error", test.name)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package jwt
+
+// raceEnabled reports whether the tests run with the race detector, which allocates.
+const raceEnabled = true
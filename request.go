@@ -0,0 +1,172 @@
+package jwt
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TokenExtractor returns the token of a request. It may be called concurrently.
+type TokenExtractor interface {
+	Extract(r *http.Request) (string, error)
+}
+
+// TokenExtractorFunc is a function returning the token of a request.
+type TokenExtractorFunc func(r *http.Request) (string, error)
+
+// Extract calls f.
+func (f TokenExtractorFunc) Extract(r *http.Request) (string, error) {
+	return f(r)
+}
+
+// BearerExtractor returns a TokenExtractor of the Authorization header, as returned by BearerToken.
+func BearerExtractor() TokenExtractor {
+	return TokenExtractorFunc(BearerToken)
+}
+
+// HeaderExtractor returns a TokenExtractor of the value of the header name, e.g. IAPHeader.
+func HeaderExtractor(name string) TokenExtractor {
+	return TokenExtractorFunc(func(r *http.Request) (string, error) {
+		token := strings.TrimSpace(r.Header.Get(name))
+		if token == "" {
+			return "", fmt.Errorf("missing %v header", name)
+		}
+		return token, nil
+	})
+}
+
+// CookieExtractor returns a TokenExtractor of the cookie name, as returned by CookieToken.
+func CookieExtractor(name string) TokenExtractor {
+	return TokenExtractorFunc(func(r *http.Request) (string, error) {
+		return CookieToken(r, name)
+	})
+}
+
+// QueryExtractor returns a TokenExtractor of the URL query parameter name.
+// Tokens in URLs may be logged, prefer a header or cookie where possible.
+func QueryExtractor(name string) TokenExtractor {
+	return TokenExtractorFunc(func(r *http.Request) (string, error) {
+		token := r.URL.Query().Get(name)
+		if token == "" {
+			return "", fmt.Errorf("missing %v query parameter", name)
+		}
+		return token, nil
+	})
+}
+
+// SubprotocolExtractor returns a TokenExtractor of the first Sec-WebSocket-Protocol subprotocol starting with prefix,
+// without prefix, e.g. of "bearer.<token>" for prefix "bearer.". Browsers can't set the Authorization header of WebSockets,
+// but can offer subprotocols. The server must not select the token subprotocol when upgrading the connection.
+func SubprotocolExtractor(prefix string) TokenExtractor {
+	return TokenExtractorFunc(func(r *http.Request) (string, error) {
+		for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+			for _, protocol := range strings.Split(value, ",") {
+				if protocol = strings.TrimSpace(protocol); strings.HasPrefix(protocol, prefix) && len(protocol) > len(prefix) {
+					return protocol[len(prefix):], nil
+				}
+			}
+		}
+		return "", fmt.Errorf("missing %v subprotocol", prefix)
+	})
+}
+
+// ChainExtractors returns a TokenExtractor returning the token of the first of extractors which succeeds.
+func ChainExtractors(extractors ...TokenExtractor) TokenExtractor {
+	return TokenExtractorFunc(func(r *http.Request) (string, error) {
+		errs := make([]string, 0, len(extractors))
+		for _, e := range extractors {
+			token, err := e.Extract(r)
+			if err == nil {
+				return token, nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return "", fmt.Errorf("no token - %v", strings.Join(errs, "; "))
+	})
+}
+
+// WithTokenExtractor sets the TokenExtractor of VerifyRequest, the default is BearerExtractor.
+func WithTokenExtractor(e TokenExtractor) Option {
+	return func(v *Verifier) {
+		v.extractor = e
+	}
+}
+
+// WithTokenCookie makes VerifyRequest take the token from the cookie name if it isn't in the Authorization header.
+func WithTokenCookie(name string) Option {
+	return WithTokenExtractor(ChainExtractors(BearerExtractor(), CookieExtractor(name)))
+}
+
+// VerifyRequest returns the verified token of r, as returned by the TokenExtractor set by WithTokenExtractor,
+// by default of the Authorization header as returned by BearerToken.
+// r.Context() is passed to the KeyFetcher if the keys need to be refreshed.
+func (v *Verifier) VerifyRequest(r *http.Request) (*JWT, error) {
+	extractor := v.extractor
+	if extractor == nil {
+		extractor = BearerExtractor()
+	}
+	ctx := r.Context()
+	if v.auditHook != nil || v.cacheConfig.log != nil {
+		ctx = SourceIPContext(r)
+	}
+	token, err := extractor.Extract(r)
+	if err != nil {
+		v.audit(ctx, nil, err)
+		return nil, v.formatError(err)
+	}
+	return v.ParseAndVerifyContext(ctx, token)
+}
+
+// CookieToken returns the token of the cookie name of r.
+func CookieToken(r *http.Request, name string) (string, error) {
+	c, err := r.Cookie(name)
+	if err != nil || c.Value == "" {
+		return "", fmt.Errorf("missing %v cookie", name)
+	}
+	return c.Value, nil
+}
+
+// GoogleSignInCredential returns the ID token posted by Sign in with Google for web to a login endpoint,
+// the credential form field. The g_csrf_token form field must match the g_csrf_token cookie.
+func GoogleSignInCredential(r *http.Request) (string, error) {
+	cookie, err := CookieToken(r, "g_csrf_token")
+	if err != nil {
+		return "", err
+	}
+	if r.PostFormValue("g_csrf_token") != cookie {
+		return "", fmt.Errorf("g_csrf_token does not match cookie")
+	}
+	credential := r.PostFormValue("credential")
+	if credential == "" {
+		return "", fmt.Errorf("missing credential")
+	}
+	return credential, nil
+}
+
+// BearerToken returns the token of the Authorization header of r.
+// The case-insensitive Bearer scheme is stripped, a header without scheme is taken as the token.
+func BearerToken(r *http.Request) (string, error) {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if auth == "" {
+		return "", fmt.Errorf("missing Authorization header")
+	}
+	scheme, token, ok := cut(auth, " ")
+	if !ok {
+		return auth, nil
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("authorization scheme %v is not Bearer", scheme)
+	}
+	if token = strings.TrimSpace(token); token == "" {
+		return "", fmt.Errorf("empty bearer token")
+	}
+	return token, nil
+}
+
+// cut is strings.Cut, which requires go 1.18.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
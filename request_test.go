@@ -0,0 +1,131 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifyRequest(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	token := testToken(t, key, nil, nil)
+
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+	}{
+		{"bearer", "Bearer " + token, true},
+		{"case insensitive scheme", "BEARER " + token, true},
+		{"extra spaces", "  Bearer   " + token + " ", true},
+		{"no scheme", token, true},
+		{"other scheme", "Basic " + token, false},
+		{"empty token", "Bearer ", false},
+		{"no header", "", false},
+		{"invalid token", "Bearer invalid", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		_, err := ver.VerifyRequest(r)
+		if test.valid && err != nil {
+			t.Errorf("%v: request verification fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid request not throwing error", test.name)
+		}
+	}
+}
+
+func TestVerifyRequestCookie(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithTokenCookie("id_token"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	token := testToken(t, key, nil, nil)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "id_token", Value: token})
+	if _, err := ver.VerifyRequest(r); err != nil {
+		t.Errorf("cookie verification fail, %v", err)
+	}
+	r.Header.Set("Authorization", "Bearer invalid")
+	if _, err := ver.VerifyRequest(r); err == nil {
+		t.Errorf("invalid Authorization header not throwing error")
+	}
+	if _, err := ver.VerifyRequest(httptest.NewRequest("GET", "/", nil)); err == nil {
+		t.Errorf("missing cookie not throwing error")
+	}
+}
+
+func TestGoogleSignInCredential(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie string
+		form   url.Values
+		valid  bool
+	}{
+		{"credential", "csrf", url.Values{"g_csrf_token": {"csrf"}, "credential": {"token"}}, true},
+		{"csrf mismatch", "csrf", url.Values{"g_csrf_token": {"other"}, "credential": {"token"}}, false},
+		{"no csrf cookie", "", url.Values{"g_csrf_token": {""}, "credential": {"token"}}, false},
+		{"no credential", "csrf", url.Values{"g_csrf_token": {"csrf"}}, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/login", strings.NewReader(test.form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if test.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "g_csrf_token", Value: test.cookie})
+		}
+		credential, err := GoogleSignInCredential(r)
+		if test.valid && (err != nil || credential != "token") {
+			t.Errorf("%v: credential %v, %v", test.name, credential, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid request not throwing error", test.name)
+		}
+	}
+}
+
+func TestTokenExtractors(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?access_token=query", nil)
+	r.Header.Set("X-Token", "header")
+	r.AddCookie(&http.Cookie{Name: "token", Value: "cookie"})
+	r.Header.Add("Sec-WebSocket-Protocol", "chat")
+	r.Header.Add("Sec-WebSocket-Protocol", "v2.chat, bearer.subprotocol")
+
+	tests := []struct {
+		name      string
+		extractor TokenExtractor
+		token     string
+	}{
+		{"header", HeaderExtractor("X-Token"), "header"},
+		{"cookie", CookieExtractor("token"), "cookie"},
+		{"query", QueryExtractor("access_token"), "query"},
+		{"chain", ChainExtractors(BearerExtractor(), HeaderExtractor("X-Missing"), QueryExtractor("access_token"), CookieExtractor("token")), "query"},
+		{"subprotocol", SubprotocolExtractor("bearer."), "subprotocol"},
+		{"custom", TokenExtractorFunc(func(r *http.Request) (string, error) { return "custom", nil }), "custom"},
+		{"missing header", HeaderExtractor("X-Missing"), ""},
+		{"missing cookie", CookieExtractor("missing"), ""},
+		{"missing query parameter", QueryExtractor("missing"), ""},
+		{"missing subprotocol", SubprotocolExtractor("access_token."), ""},
+		{"chain failure", ChainExtractors(BearerExtractor(), CookieExtractor("missing")), ""},
+	}
+	for _, test := range tests {
+		token, err := test.extractor.Extract(r)
+		if test.token != "" && (err != nil || token != test.token) {
+			t.Errorf("%v: extracted %v, %v", test.name, token, err)
+		}
+		if test.token == "" && err == nil {
+			t.Errorf("%v: missing token not throwing error", test.name)
+		}
+	}
+}
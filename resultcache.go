@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// WithResultCache caches up to size verified tokens by their SHA-256 hash, a cached token is returned without being
+// verified again until it expires, e.g. for services which see the same bearer tokens many times.
+// A cached token isn't affected by key rotations until it expires. The cache isn't used with WithIntrospection,
+// so that every token is introspected and revoked tokens are rejected.
+// The least recently used token is evicted when the cache is full, a non-positive size disables the cache.
+func WithResultCache(size int) Option {
+	return func(v *Verifier) {
+		v.results = nil
+		if size > 0 {
+			v.results = newResultCache(size)
+		}
+	}
+}
+
+// resultCache is an LRU cache of verified tokens.
+type resultCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List // the *resultEntry values, the most recently used first
+}
+
+type resultEntry struct {
+	hash  [sha256.Size]byte
+	token *JWT
+}
+
+func newResultCache(size int) *resultCache {
+	return &resultCache{size: size, entries: make(map[[sha256.Size]byte]*list.Element), lru: list.New()}
+}
+
+// get returns a copy of the token with hash which is unexpired at now, nil if it's not cached.
+func (c *resultCache) get(hash [sha256.Size]byte, now time.Time) *JWT {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[hash]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*resultEntry)
+	if entry.token.Claims.EXP <= now.Unix() {
+		c.lru.Remove(e)
+		delete(c.entries, hash)
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return copyJWT(entry.token)
+}
+
+// add caches a copy of the verified token with hash, evicting the least recently used token if the cache is full.
+func (c *resultCache) add(hash [sha256.Size]byte, token *JWT) {
+	t := copyJWT(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[hash]; ok {
+		e.Value.(*resultEntry).token = t
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[hash] = c.lru.PushFront(&resultEntry{hash: hash, token: t})
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).hash)
+	}
+}
+
+// copyJWT returns a deep copy of token, so that the cached tokens don't share slices with the returned tokens.
+func copyJWT(token *JWT) *JWT {
+	t := *token
+	t.Header.CRIT = append([]string(nil), token.Header.CRIT...)
+	t.Claims.AUD = append(Audience(nil), token.Claims.AUD...)
+	t.rawHeader = append([]byte(nil), token.rawHeader...)
+	t.rawClaims = append([]byte(nil), token.rawClaims...)
+	return &t
+}
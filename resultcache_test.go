@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithResultCache(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	rec := &stageRecorder{countingRecorder: countingRecorder{verifications: make(map[string]int)}, stages: make(map[string]int)}
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithResultCache(1), WithMetrics(rec))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	a := testToken(t, key, nil, map[string]interface{}{"sub": "a"})
+	b := testToken(t, key, nil, map[string]interface{}{"sub": "b"})
+	for _, v := range []struct {
+		token      string
+		signatures int
+	}{
+		{token: a, signatures: 1},
+		{token: a, signatures: 1},
+		{token: b, signatures: 2},
+		{token: a, signatures: 3}, // evicted by b
+	} {
+		token, err := ver.ParseAndVerify(v.token)
+		if err != nil {
+			t.Fatalf("token parse fail, %v", err)
+		}
+		if rec.stages["signature"] != v.signatures {
+			t.Errorf("expected %v signature verifications, got %v", v.signatures, rec.stages["signature"])
+		}
+		token.Claims.SUB = "modified"
+		token.Claims.AUD[0] = "modified"
+	}
+
+	cached, err := ver.ParseAndVerify(a)
+	if err != nil || cached.Claims.SUB != "a" || !equalStrings(cached.Claims.AUD, []string{testClientID}) {
+		t.Errorf("cached token modified by caller, %v", err)
+	}
+	cached.Claims.AUD[0] = "modified"
+	if cached, err := ver.ParseAndVerify(a); err != nil || !equalStrings(cached.Claims.AUD, []string{testClientID}) {
+		t.Errorf("cached audience modified by caller, %v", err)
+	}
+
+	ver.results.entries[sha256.Sum256([]byte(a))].Value.(*resultEntry).token.Claims.EXP = time.Now().Unix()
+	if ver.results.get(sha256.Sum256([]byte(a)), time.Now()) != nil {
+		t.Errorf("expired token returned by cache")
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"aud": "other"})); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+	if len(ver.results.entries) != 0 {
+		t.Errorf("invalid token cached")
+	}
+}
+
+func TestResultCacheIntrospection(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	introspections := 0
+	active := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		introspections++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"active": %v}`, active)
+	}))
+	defer srv.Close()
+	ver, err := NewVerifier(keyGetterFunc(jwks), testClientID, WithResultCache(10), WithIntrospection(NewIntrospector(srv.URL, "rs", "secret", nil)))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	token := testToken(t, key, nil, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := ver.ParseAndVerify(token); err != nil {
+			t.Fatalf("token parse fail, %v", err)
+		}
+	}
+	if introspections != 2 {
+		t.Errorf("expected 2 introspections, got %v", introspections)
+	}
+	active = false
+	if _, err := ver.ParseAndVerify(token); !errors.Is(err, ErrInactive) {
+		t.Errorf("expected revoked token to be inactive, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"context"
+	"sort"
+)
+
+// KeyRotation is a change of the cached key set, passed to the hook of WithKeyRotationHook.
+type KeyRotation struct {
+	// Added and Removed are the sorted key IDs which were added to and removed from the key set.
+	Added, Removed []string
+}
+
+// WithKeyRotationHook calls hook whenever a refresh adds or removes key IDs from the cached key set,
+// e.g. to log rotations and correlate them with ErrKeyNotFound failures. The initial key set isn't reported.
+// ctx is the context of the refresh. hook is called synchronously while the keys are refreshed.
+// Rotations are also logged by WithLogger.
+func WithKeyRotationHook(hook func(ctx context.Context, r KeyRotation)) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.rotationHook = hook
+	}
+}
+
+// diffKeys returns the key IDs added and removed from old by keys.
+func diffKeys(old, keys map[string]verificationKey) KeyRotation {
+	var r KeyRotation
+	for kid := range keys {
+		if _, ok := old[kid]; !ok {
+			r.Added = append(r.Added, kid)
+		}
+	}
+	for kid := range old {
+		if _, ok := keys[kid]; !ok {
+			r.Removed = append(r.Removed, kid)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Removed)
+	return r
+}
+
+// reportRotation logs r and calls the rotation hook, if any, unless r is empty.
+func (v *keyCache) reportRotation(ctx context.Context, r KeyRotation) {
+	if len(r.Added) == 0 && len(r.Removed) == 0 {
+		return
+	}
+	v.config.log.log(ctx, levelInfo, "jwt: keys rotated", "added", r.Added, "removed", r.Removed)
+	if v.config.rotationHook != nil {
+		v.config.rotationHook(ctx, r)
+	}
+}
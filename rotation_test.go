@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithKeyRotationHook(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	old, err := ParseJWKS(strings.NewReader(validKey))
+	if err != nil {
+		t.Fatalf("parse JWKS failed, %v", err)
+	}
+	var removed []string
+	for kid := range old {
+		removed = append(removed, kid)
+	}
+	sort.Strings(removed)
+
+	keys := validKey
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		return io.NopCloser(strings.NewReader(keys)), time.Now().Add(-time.Second), nil
+	}
+	var rotations []KeyRotation
+	ver, err := NewVerifier(fetcher, testClientID, WithKeyRotationHook(func(ctx context.Context, r KeyRotation) {
+		rotations = append(rotations, r)
+	}))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if len(rotations) != 0 {
+		t.Errorf("initial key set reported as rotation %v", rotations)
+	}
+
+	keys = jwks
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if len(rotations) != 1 || !equalStrings(rotations[0].Added, []string{"test"}) || !equalStrings(rotations[0].Removed, removed) {
+		t.Errorf("unexpected rotations %v", rotations)
+	}
+
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if len(rotations) != 1 {
+		t.Errorf("unchanged key set reported as rotation %v", rotations)
+	}
+}
@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// claimList is a claim which issuers encode as a space separated string or as an array of strings.
+type claimList []string
+
+// UnmarshalJSON decodes a space separated JSON string or an array of strings.
+func (l *claimList) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l = strings.Fields(s)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("claim is neither a string nor an array of strings - %w", err)
+	}
+	*l = list
+	return nil
+}
+
+// Scopes returns the scopes granted to the token, of the RFC 8693 scope claim and of the scp claim used by Azure AD and Okta.
+// A claim may be a space separated string or an array. Tokens with malformed claims have no scopes.
+func (t *JWT) Scopes() []string {
+	var claims struct {
+		Scope claimList `json:"scope"`
+		SCP   claimList `json:"scp"`
+	}
+	if err := t.UnmarshalClaims(&claims); err != nil {
+		return nil
+	}
+	return append(claims.Scope, claims.SCP...)
+}
+
+// Roles returns the roles of the token, of the roles claim and of the groups claim used by Azure AD and Okta.
+// A claim may be a space separated string or an array. Tokens with malformed claims have no roles.
+// Keycloak roles are decoded with KeycloakClaims.
+func (t *JWT) Roles() []string {
+	var claims struct {
+		Roles  claimList `json:"roles"`
+		Groups claimList `json:"groups"`
+	}
+	if err := t.UnmarshalClaims(&claims); err != nil {
+		return nil
+	}
+	return append(claims.Roles, claims.Groups...)
+}
+
+// HasScope reports whether scope is one of the Scopes of the token.
+func (t *JWT) HasScope(scope string) bool {
+	return containsString(t.Scopes(), scope)
+}
+
+// HasRole reports whether role is one of the Roles of the token.
+func (t *JWT) HasRole(role string) bool {
+	return containsString(t.Roles(), role)
+}
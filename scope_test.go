@@ -0,0 +1,49 @@
+package jwt
+
+import (
+	"testing"
+)
+
+func TestScopesAndRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims string
+		scopes []string
+		roles  []string
+	}{
+		{"strings", `{"scope":"openid email","roles":"admin"}`, []string{"openid", "email"}, []string{"admin"}},
+		{"arrays", `{"scp":["read","write"],"groups":["staff"],"roles":["admin"]}`, []string{"read", "write"}, []string{"admin", "staff"}},
+		{"both scope claims", `{"scope":"openid","scp":"read"}`, []string{"openid", "read"}, nil},
+		{"none", `{}`, nil, nil},
+		{"malformed", `{"scope":1,"roles":{}}`, nil, nil},
+	}
+	for _, test := range tests {
+		token := &JWT{rawClaims: []byte(test.claims)}
+		if got := token.Scopes(); !equalStrings(got, test.scopes) {
+			t.Errorf("%v: unexpected scopes %v", test.name, got)
+		}
+		if got := token.Roles(); !equalStrings(got, test.roles) {
+			t.Errorf("%v: unexpected roles %v", test.name, got)
+		}
+	}
+
+	token := &JWT{rawClaims: []byte(`{"scope":"openid email","roles":["admin"]}`)}
+	if !token.HasScope("email") || token.HasScope("profile") {
+		t.Errorf("unexpected HasScope result")
+	}
+	if !token.HasRole("admin") || token.HasRole("editor") {
+		t.Errorf("unexpected HasRole result")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
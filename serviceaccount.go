@@ -0,0 +1,102 @@
+package jwt
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewServiceAccountKeyFetcher returns an HTTPKeyFetcher which obtains the public keys of a Google service account.
+func NewServiceAccountKeyFetcher(email string, opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher("https://www.googleapis.com/service_accounts/v1/jwk/"+url.PathEscape(email), opts...)
+}
+
+// NewServiceAccountVerifier returns a Verifier which parses and verifies tokens signed by the key of a Google service account,
+// e.g. with the IAM signJwt method, issued to audience.
+// Tokens are verified with the keys of NewServiceAccountKeyFetcher and their issuer and subject must be email.
+func NewServiceAccountVerifier(email, audience string, opts ...Option) (*Verifier, error) {
+	return newServiceAccountVerifier(context.Background(), NewServiceAccountKeyFetcher(email), email, audience, opts)
+}
+
+func newServiceAccountVerifier(ctx context.Context, keyFetcher KeyFetcher, email, audience string, opts []Option) (*Verifier, error) {
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid service account email %v", email)
+	}
+	opts = append([]Option{withChecks(func(token *JWT) error {
+		if token.Claims.SUB != email {
+			return fmt.Errorf("subject %v does not match service account", token.Claims.SUB)
+		}
+		return nil
+	})}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, email, audience, opts)
+}
+
+// The lifetime of service account ID tokens, and the time before their expiration at which they are replaced.
+const (
+	serviceAccountTokenLifetime = time.Hour
+	serviceAccountTokenRefresh  = 5 * time.Minute
+)
+
+// NewServiceAccountIDTokenFunc returns an AccessTokenFunc which returns ID tokens for audience, self-signed with the private key
+// of a Google service account key file in the JSON format created by the IAM API, e.g. for WithBearerToken.
+// The tokens have the issuer and subject of the service account email and are verified by NewServiceAccountVerifier,
+// and by Identity-Aware Proxy if audience is the URL of the resource, e.g. https://app.example.com/*.
+// A token is valid for an hour and reused until 5 minutes before its expiration.
+func NewServiceAccountIDTokenFunc(keyFile []byte, audience string) (AccessTokenFunc, error) {
+	var f struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+		PrivateKey   string `json:"private_key"`
+	}
+	if err := json.Unmarshal(keyFile, &f); err != nil {
+		return nil, fmt.Errorf("decode service account key file - %w", err)
+	}
+	if f.Type != "service_account" || !strings.Contains(f.ClientEmail, "@") {
+		return nil, fmt.Errorf("not a service account key file")
+	}
+	block, _ := pem.Decode([]byte(f.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("parse service account private key - %w", err)
+		}
+	}
+	signer, err := NewSigner(key, f.PrivateKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var token string
+	var expires time.Time
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if token != "" && now.Add(serviceAccountTokenRefresh).Before(expires) {
+			return token, nil
+		}
+		t, err := signer.Sign(Claims{
+			ISS: f.ClientEmail,
+			SUB: f.ClientEmail,
+			AUD: Audience{audience},
+			IAT: now.Unix(),
+			EXP: now.Add(serviceAccountTokenLifetime).Unix(),
+		})
+		if err != nil {
+			return "", err
+		}
+		token, expires = t, now.Add(serviceAccountTokenLifetime)
+		return token, nil
+	}, nil
+}
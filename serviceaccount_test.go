@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+)
+
+func TestServiceAccountVerifier(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	const email = "invoker@my-project.iam.gserviceaccount.com"
+	ver, err := newServiceAccountVerifier(context.Background(), keyGetterFunc(jwks), email, "https://api.example.com", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"self signed", map[string]interface{}{"iss": email, "sub": email, "aud": "https://api.example.com"}, true},
+		{"other subject", map[string]interface{}{"iss": email, "sub": "other@my-project.iam.gserviceaccount.com", "aud": "https://api.example.com"}, false},
+		{"google issuer", map[string]interface{}{"sub": email, "aud": "https://api.example.com"}, false},
+		{"other audience", map[string]interface{}{"iss": email, "sub": email, "aud": "https://other.example.com"}, false},
+	}
+	for _, test := range tests {
+		_, err = ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	if got := NewServiceAccountKeyFetcher(email).URL(); got != "https://www.googleapis.com/service_accounts/v1/jwk/"+email {
+		t.Errorf("unexpected key URL %v", got)
+	}
+}
+
+func TestServiceAccountIDTokenFunc(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key failed, %v", err)
+	}
+	const email = "invoker@my-project.iam.gserviceaccount.com"
+	keyFile, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   email,
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatalf("marshal key file failed, %v", err)
+	}
+	tokenFunc, err := NewServiceAccountIDTokenFunc(keyFile, "https://api.example.com")
+	if err != nil {
+		t.Fatalf("New ID token func failed, %v", err)
+	}
+	token, err := tokenFunc(context.Background())
+	if err != nil {
+		t.Fatalf("mint token failed, %v", err)
+	}
+	if cached, err := tokenFunc(context.Background()); err != nil || cached != token {
+		t.Errorf("token not reused, %v", err)
+	}
+
+	jwks := fmt.Sprintf(`{"keys": [{"kty":"RSA","kid":"key-id","e":"AQAB","n":"%v"}]}`, base64.RawURLEncoding.EncodeToString(key.N.Bytes()))
+	ver, err := newServiceAccountVerifier(context.Background(), keyGetterFunc(jwks), email, "https://api.example.com", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(token); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	if _, err := NewServiceAccountIDTokenFunc([]byte(`{"type":"authorized_user"}`), "https://api.example.com"); err == nil {
+		t.Errorf("user credentials not throwing error")
+	}
+}
@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+)
+
+// sharedCaches holds the process wide key caches of WithSharedCache, mapped by their key.
+var sharedCaches = struct {
+	mu      sync.Mutex
+	entries map[string]*sharedCache
+}{entries: make(map[string]*sharedCache)}
+
+// sharedCache is a key cache referenced by refs Verifiers.
+type sharedCache struct {
+	mu    sync.Mutex // held while the cache is created
+	cache *keyCache
+	refs  int
+}
+
+// WithSharedCache shares the key cache, including its refresh schedule, of all Verifiers created with the same key,
+// typically the JWKS url, e.g. verifiers of the same issuer for different audiences.
+// The KeyFetcher and cache options of the first Verifier created with key are used, those of later Verifiers are ignored.
+// The cache is released when all Verifiers sharing it are closed.
+func WithSharedCache(key string) Option {
+	return func(v *Verifier) {
+		v.sharedKey = key
+	}
+}
+
+// acquireSharedCache returns the shared cache of key, creating it if it doesn't exist.
+func acquireSharedCache(ctx context.Context, key string, keyFetcher KeyFetcher, config cacheConfig) (*keyCache, error) {
+	sharedCaches.mu.Lock()
+	e := sharedCaches.entries[key]
+	if e == nil {
+		e = &sharedCache{}
+		sharedCaches.entries[key] = e
+	}
+	e.refs++
+	sharedCaches.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cache == nil {
+		c, err := newKeyCache(ctx, keyFetcher, config)
+		if err != nil {
+			// the failed cache is not shared, a later Verifier tries again
+			releaseSharedCache(context.Background(), key)
+			return c, err
+		}
+		e.cache = c
+	}
+	return e.cache, nil
+}
+
+// releaseSharedCache drops a reference to the shared cache of key, the cache is shut down when unreferenced.
+func releaseSharedCache(ctx context.Context, key string) error {
+	sharedCaches.mu.Lock()
+	e := sharedCaches.entries[key]
+	e.refs--
+	if e.refs > 0 {
+		sharedCaches.mu.Unlock()
+		return nil
+	}
+	delete(sharedCaches.entries, key)
+	sharedCaches.mu.Unlock()
+
+	if e.cache == nil {
+		return nil
+	}
+	return e.cache.shutdown(ctx)
+}
@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithSharedCache(t *testing.T) {
+	var fetches int32
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		atomic.AddInt32(&fetches, 1)
+		return io.NopCloser(strings.NewReader(validKey)), time.Now().Add(time.Hour), nil
+	}
+
+	ver1, err := NewVerifier(fetcher, testClientID, WithSharedCache("test"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	ver2, err := NewVerifierContext(context.Background(), fetcher, "other.apps.googleusercontent.com", WithSharedCache("test"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected 1 fetch, got %v", n)
+	}
+
+	ver2.Close()
+	ver2.Close()
+	if _, err := ver1.ParseAndVerify(validToken); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	ver1.Close()
+
+	if _, err := NewVerifier(fetcher, testClientID, WithSharedCache("test")); err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected released cache to be fetched again, got %v fetches", n)
+	}
+}
@@ -0,0 +1,103 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WithSignedJWKS expects the fetched key set to be a JWT (application/jwk-set+jwt) signed by one of the anchors keys,
+// e.g. when the key set is mirrored through untrusted infrastructure.
+// Its claims are the key set, either as is or in the "jwks" claim of an OpenID Federation entity statement.
+// The keys are used only if the signature is valid and the JWT has an exp claim, as entity statements do,
+// which isn't expired at the clock of the Verifier, so that a mirror can't replay an old key set indefinitely.
+func WithSignedJWKS(anchors map[string]crypto.PublicKey) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.jwks.anchors = anchors
+	}
+}
+
+// ParseSignedJWKS returns the public keys of a JSON Web Key Set which is signed as a JWT by one of the anchors keys.
+// The key set must have an exp claim and not be expired. It's parsed and limited as by ParseJWKS.
+func ParseSignedJWKS(r io.Reader, anchors map[string]crypto.PublicKey) (map[string]crypto.PublicKey, error) {
+	c := defaultJWKSConfig
+	c.anchors = anchors
+	keys, err := c.parse(r)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]crypto.PublicKey, len(keys))
+	for kid, k := range keys {
+		m[kid] = k.key
+	}
+	return m, nil
+}
+
+// verifySigned verifies the signed key set b with the anchors and returns the key set.
+func (c jwksConfig) verifySigned(b []byte) ([]byte, error) {
+	parts := strings.Split(string(bytes.TrimSpace(b)), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("signed key set has %v parts, expected 3", len(parts))
+	}
+	h, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode %v, %w", parts[0], err)
+	}
+	var header Header
+	if err := json.Unmarshal(h, &header); err != nil {
+		return nil, fmt.Errorf("unable to json decode %s, %w", h, err)
+	}
+	if header.TYP != "" && header.TYP != "jwk-set+jwt" && header.TYP != "entity-statement+jwt" {
+		return nil, fmt.Errorf("unexpected signed key set typ %v", header.TYP)
+	}
+
+	anchors := c.anchors
+	if header.KID != "" {
+		k, ok := c.anchors[header.KID]
+		if !ok {
+			return nil, fmt.Errorf("no trust anchor with kid %v", header.KID)
+		}
+		anchors = map[string]crypto.PublicKey{header.KID: k}
+	}
+	verified := false
+	for _, k := range anchors {
+		if verifySignature(parts[0]+"."+parts[1], parts[2], header.ALG, k) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("signed key set verification failed")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("unable to base64 decode %v, %w", parts[1], err)
+	}
+	var claims struct {
+		EXP  int64           `json:"exp"`
+		JWKS json.RawMessage `json:"jwks"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("unable to json decode %s, %w", payload, err)
+	}
+	if claims.EXP == 0 {
+		return nil, fmt.Errorf("signed key set has no exp claim")
+	}
+	now := time.Now()
+	if c.clock != nil {
+		now = c.clock()
+	}
+	if time.Unix(claims.EXP, 0).Before(now) {
+		return nil, fmt.Errorf("signed key set expired at %v", time.Unix(claims.EXP, 0))
+	}
+	if claims.JWKS != nil {
+		return claims.JWKS, nil
+	}
+	return payload, nil
+}
@@ -0,0 +1,84 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedJWKS(t *testing.T) {
+	_, anchor, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	anchors := map[string]crypto.PublicKey{"anchor": anchor.Public()}
+
+	var keySet map[string]interface{}
+	if err := json.Unmarshal([]byte(validKey), &keySet); err != nil {
+		t.Fatalf("unmarshal key set failed, %v", err)
+	}
+	header := map[string]interface{}{"typ": "jwk-set+jwt", "kid": "anchor"}
+	keysClaims := map[string]interface{}{"keys": keySet["keys"]}
+	federationClaims := map[string]interface{}{"jwks": keySet}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"key set claims", testToken(t, anchor, header, keysClaims), true},
+		{"entity statement jwks claim", testToken(t, anchor, header, federationClaims), true},
+		{"no kid", testToken(t, anchor, map[string]interface{}{"typ": "jwk-set+jwt", "kid": nil}, keysClaims), true},
+		{"unpinned signer", testToken(t, other, header, keysClaims), false},
+		{"unknown kid", testToken(t, anchor, map[string]interface{}{"kid": "other"}, keysClaims), false},
+		{"wrong typ", testToken(t, anchor, map[string]interface{}{"typ": "JWT", "kid": "anchor"}, keysClaims), false},
+		{"expired", testToken(t, anchor, header, map[string]interface{}{"keys": keySet["keys"], "exp": time.Now().Add(-time.Minute).Unix()}), false},
+		{"no exp", testToken(t, anchor, header, map[string]interface{}{"keys": keySet["keys"], "exp": nil}), false},
+		{"unsigned key set", validKey, false},
+	}
+	for _, test := range tests {
+		keys, err := ParseSignedJWKS(strings.NewReader(test.token), anchors)
+		if test.valid && (err != nil || keys["f73e9e2b-242e-4842-8809-65ba74800972"] == nil) {
+			t.Errorf("%v: parse failed, %v %v", test.name, keys, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid key set not throwing error", test.name)
+		}
+	}
+}
+
+func TestSignedJWKSClock(t *testing.T) {
+	_, anchor, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	key, jwks := testEd25519Key(t)
+	var keySet map[string]interface{}
+	if err := json.Unmarshal([]byte(jwks), &keySet); err != nil {
+		t.Fatalf("unmarshal key set failed, %v", err)
+	}
+	past := time.Now().Add(-time.Hour * 2)
+	signed := testToken(t, anchor, map[string]interface{}{"typ": "jwk-set+jwt", "kid": "anchor"},
+		map[string]interface{}{"keys": keySet["keys"], "iat": past.Unix(), "exp": past.Add(time.Hour).Unix()})
+	anchors := map[string]crypto.PublicKey{"anchor": anchor.Public()}
+
+	if _, err := NewVerifier(keyGetterFunc(signed), testClientID, WithSignedJWKS(anchors)); err == nil {
+		t.Errorf("expired signed key set not throwing error")
+	}
+	ver, err := NewVerifier(keyGetterFunc(signed), testClientID, WithSignedJWKS(anchors), WithClock(func() time.Time { return past }))
+	if err != nil {
+		t.Fatalf("signed key set not verified at the verifier clock, %v", err)
+	}
+	token := testToken(t, key, nil, map[string]interface{}{"iat": past.Unix(), "exp": past.Add(time.Hour).Unix()})
+	if _, err := ver.ParseAndVerify(token); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+}
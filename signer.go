@@ -0,0 +1,131 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Signer signs tokens, e.g. service to service tokens verified by a Verifier with the public key of the Signer.
+// It may be used concurrently.
+type Signer struct {
+	alg    string
+	kid    string
+	public crypto.PublicKey
+	// sign returns the alg signature of signed
+	sign func(signed []byte) ([]byte, error)
+}
+
+// NewSigner returns a Signer which signs tokens with key and sets their kid header to kid.
+// key is an *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey, or any crypto.Signer with such a public key,
+// e.g. a key held in an HSM or a cloud KMS. The alg of the tokens depends on the public key:
+// RS256 for an RSA key of at least 2048 bits, ES256 for a P-256 ECDSA key and EdDSA for an Ed25519 key.
+func NewSigner(key crypto.PrivateKey, kid string) (*Signer, error) {
+	if k, ok := key.(ed25519.PrivateKey); ok && len(k) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("Ed25519 signing key of %v bytes, expected %v", len(k), ed25519.PrivateKeySize)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		if key == nil {
+			return nil, fmt.Errorf("nil signing key")
+		}
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+	switch pub := signer.Public().(type) {
+	case *rsa.PublicKey:
+		if bits := pub.N.BitLen(); bits < 2048 {
+			return nil, fmt.Errorf("RSA signing key of %v bits, at least 2048 bits are required", bits)
+		}
+		return &Signer{alg: "RS256", kid: kid, public: pub, sign: func(signed []byte) ([]byte, error) {
+			hashed := sha256.Sum256(signed)
+			return signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+		}}, nil
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ECDSA signing key on curve %v, only P-256 is supported", pub.Curve.Params().Name)
+		}
+		return &Signer{alg: "ES256", kid: kid, public: pub, sign: func(signed []byte) ([]byte, error) {
+			hashed := sha256.Sum256(signed)
+			der, err := signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+			if err != nil {
+				return nil, err
+			}
+			return rawECDSASignature(der, 32)
+		}}, nil
+	case ed25519.PublicKey:
+		return &Signer{alg: "EdDSA", kid: kid, public: pub, sign: func(signed []byte) ([]byte, error) {
+			// Ed25519 signs the message itself rather than a digest
+			return signer.Sign(rand.Reader, signed, crypto.Hash(0))
+		}}, nil
+	}
+	return nil, fmt.Errorf("unsupported signing public key type %T", signer.Public())
+}
+
+// rawECDSASignature converts the ASN.1 DER ECDSA signature returned by crypto.Signer
+// to the concatenation of the size byte r and s values used by JWS.
+func rawECDSASignature(der []byte, size int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("decode ECDSA signature - %w", err)
+	}
+	if len(rest) != 0 || sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > size*8 || sig.S.BitLen() > size*8 {
+		return nil, fmt.Errorf("malformed ECDSA signature")
+	}
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// Sign returns the compact serialization of a token with claims encoded as JSON, typically Claims
+// or a struct which embeds Claims along with other claims:
+//
+//	token, err := signer.Sign(jwt.Claims{
+//		ISS: "https://auth.example.com",
+//		AUD: jwt.Audience{"https://api.example.com"},
+//		SUB: "service-a",
+//		IAT: time.Now().Unix(),
+//		EXP: time.Now().Add(time.Hour).Unix(),
+//	})
+func (s *Signer) Sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(struct {
+		ALG string `json:"alg"`
+		KID string `json:"kid,omitempty"`
+		TYP string `json:"typ"`
+	}{ALG: s.alg, KID: s.kid, TYP: "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("marshal header - %w", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal claims - %w", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := s.sign([]byte(signed))
+	if err != nil {
+		return "", fmt.Errorf("sign token - %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Alg returns the alg header of the tokens signed by the Signer.
+func (s *Signer) Alg() string {
+	return s.alg
+}
+
+// Public returns the public key of the Signer, e.g. for NewVerifierWithKeys.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.public
+}
@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	s, err := NewSigner(key, "test")
+	if err != nil {
+		t.Fatalf("New Signer failed, %v", err)
+	}
+	claims := struct {
+		Claims
+		Tenant string `json:"tenant"`
+	}{
+		Claims: Claims{
+			ISS: "https://auth.example.com",
+			AUD: Audience{testClientID},
+			SUB: "service-a",
+			IAT: time.Now().Unix(),
+			EXP: time.Now().Add(time.Hour).Unix(),
+		},
+		Tenant: "t1",
+	}
+	token, err := s.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign failed, %v", err)
+	}
+
+	ver, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"test": s.Public()}, testClientID, WithIssuer("https://auth.example.com"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	verified, err := ver.ParseAndVerify(token)
+	if err != nil {
+		t.Fatalf("token parse fail, %v", err)
+	}
+	if verified.Header.ALG != "RS256" || verified.Header.KID != "test" || verified.Header.TYP != "JWT" || verified.Claims.SUB != "service-a" {
+		t.Errorf("unexpected token %+v", verified)
+	}
+	var custom struct {
+		Tenant string `json:"tenant"`
+	}
+	if err := verified.UnmarshalClaims(&custom); err != nil || custom.Tenant != "t1" {
+		t.Errorf("unexpected custom claims %+v, %v", custom, err)
+	}
+	if strings.Contains(string(verified.rawClaims), "email") {
+		t.Errorf("empty claims encoded, %s", verified.rawClaims)
+	}
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	for _, key := range []crypto.PrivateKey{nil, p384, "key"} {
+		if _, err := NewSigner(key, "unsupported"); err == nil {
+			t.Errorf("unsupported key %T not throwing error", key)
+		}
+	}
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	if _, err := NewSigner(small, "small"); err == nil {
+		t.Errorf("1024 bit key not throwing error")
+	}
+}
+
+func TestSignerAlgs(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	for _, v := range []struct {
+		key crypto.PrivateKey
+		alg string
+	}{
+		{key: ecKey, alg: "ES256"},
+		{key: edKey, alg: "EdDSA"},
+	} {
+		s, err := NewSigner(v.key, "test")
+		if err != nil {
+			t.Fatalf("New Signer failed, %v", err)
+		}
+		if s.Alg() != v.alg {
+			t.Errorf("expected alg %v, got %v", v.alg, s.Alg())
+		}
+		ver, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"test": s.Public()}, testClientID)
+		if err != nil {
+			t.Fatalf("New Verifier failed, %v", err)
+		}
+		// many ES256 signatures have r or s with leading zero bytes, which must be padded
+		for i := 0; i < 20; i++ {
+			token, err := s.Sign(Claims{ISS: "https://accounts.google.com", AUD: Audience{testClientID}, EXP: time.Now().Add(time.Hour).Unix()})
+			if err != nil {
+				t.Fatalf("sign failed, %v", err)
+			}
+			verified, err := ver.ParseAndVerify(token)
+			if err != nil {
+				t.Fatalf("%v token parse fail, %v", v.alg, err)
+			}
+			if verified.Header.ALG != v.alg {
+				t.Errorf("unexpected alg header %v", verified.Header.ALG)
+			}
+		}
+	}
+}
+
+// hsmKey is a crypto.Signer which doesn't expose its private key, like a key held in an HSM.
+type hsmKey struct {
+	signer crypto.Signer
+	signs  int
+}
+
+func (k *hsmKey) Public() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+func (k *hsmKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	k.signs++
+	return k.signer.Sign(rand, digest, opts)
+}
+
+func TestSignerCryptoSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	for _, key := range []crypto.Signer{rsaKey, ecKey, edKey} {
+		hsm := &hsmKey{signer: key}
+		s, err := NewSigner(hsm, "test")
+		if err != nil {
+			t.Fatalf("New Signer failed, %v", err)
+		}
+		ver, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"test": s.Public()}, testClientID)
+		if err != nil {
+			t.Fatalf("New Verifier failed, %v", err)
+		}
+		token, err := s.Sign(Claims{ISS: "https://accounts.google.com", AUD: Audience{testClientID}, EXP: time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("sign failed, %v", err)
+		}
+		if _, err := ver.ParseAndVerify(token); err != nil {
+			t.Errorf("%v token parse fail, %v", s.Alg(), err)
+		}
+		if hsm.signs != 1 {
+			t.Errorf("expected 1 sign, got %v", hsm.signs)
+		}
+	}
+
+	if _, err := NewSigner(unsupportedSigner{}, "test"); err == nil {
+		t.Errorf("unsupported public key not throwing error")
+	}
+}
+
+// unsupportedSigner is a crypto.Signer with an unsupported public key.
+type unsupportedSigner struct{}
+
+func (unsupportedSigner) Public() crypto.PublicKey {
+	return "key"
+}
+
+func (unsupportedSigner) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("sign failed")
+}
@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package jwt
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger logs the events of the Verifier with logger: key refreshes at slog.LevelInfo, key fetch failures at slog.LevelWarn,
+// expired and unmodified keys at slog.LevelDebug and failed verifications, without token material, at failureLevel.
+// The Verifier is silent by default.
+func WithLogger(logger *slog.Logger, failureLevel slog.Level) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.log = slogFunc(logger)
+		v.failureLevel = logLevel(failureLevel)
+	}
+}
+
+// WithHTTPLogger logs the retries of failed requests at slog.LevelWarn with logger.
+func WithHTTPLogger(logger *slog.Logger) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.log = slogFunc(logger)
+	}
+}
+
+// slogFunc returns a logFunc logging with logger.
+func slogFunc(logger *slog.Logger) logFunc {
+	return func(ctx context.Context, level logLevel, msg string, args ...interface{}) {
+		logger.Log(ctx, slog.Level(level), msg, args...)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build go1.21
+
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	fails := 1
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		if fails > 0 {
+			fails--
+			return nil, time.Time{}, io.ErrUnexpectedEOF
+		}
+		return io.NopCloser(strings.NewReader(jwks)), time.Now().Add(time.Hour), nil
+	}
+	if _, err := NewVerifier(fetcher, testClientID, WithLogger(logger, slog.LevelWarn)); err == nil {
+		t.Fatalf("failed fetch not throwing error")
+	}
+	ver, err := NewVerifier(fetcher, testClientID, WithLogger(logger, slog.LevelWarn))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	token := testToken(t, key, nil, map[string]interface{}{"aud": "other"})
+	if _, err := ver.ParseAndVerify(token); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="jwt: key fetch failed"`,
+		`level=INFO msg="jwt: keys refreshed" keys=1`,
+		`level=WARN msg="jwt: verification failed" code=aud_mismatch iss=https://accounts.google.com kid=test`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("%v not logged in %v", want, logs)
+		}
+	}
+	if strings.Contains(logs, token) {
+		t.Errorf("token logged")
+	}
+}
+
+func TestWithHTTPLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, validKey)
+	}))
+	defer srv.Close()
+
+	f := NewHTTPKeyFetcher(srv.URL, WithRetry(1, time.Millisecond, time.Millisecond), WithHTTPLogger(logger))
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+	if !strings.Contains(buf.String(), `level=WARN msg="jwt: retrying key fetch"`) {
+		t.Errorf("retry not logged in %v", buf.String())
+	}
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SPIFFEID is a SPIFFE ID, spiffe://<TrustDomain><Path>.
+type SPIFFEID struct {
+	TrustDomain string
+	Path        string // empty or starting with a slash
+}
+
+// String returns the SPIFFE ID URI.
+func (id SPIFFEID) String() string {
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+// ParseSPIFFEID parses a SPIFFE ID URI as specified by the SPIFFE ID standard.
+func ParseSPIFFEID(id string) (SPIFFEID, error) {
+	rest := strings.TrimPrefix(id, "spiffe://")
+	if rest == id {
+		return SPIFFEID{}, fmt.Errorf("SPIFFE ID %q doesn't have the spiffe scheme", id)
+	}
+	td, path := rest, ""
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		td, path = rest[:i], rest[i:]
+	}
+	if td == "" {
+		return SPIFFEID{}, fmt.Errorf("SPIFFE ID %q has no trust domain", id)
+	}
+	for _, c := range td {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return SPIFFEID{}, fmt.Errorf("SPIFFE ID %q has invalid trust domain character %q", id, c)
+		}
+	}
+	if path != "" {
+		for _, segment := range strings.Split(path[1:], "/") {
+			if segment == "" || segment == "." || segment == ".." {
+				return SPIFFEID{}, fmt.Errorf("SPIFFE ID %q has invalid path segment %q", id, segment)
+			}
+			for _, c := range segment {
+				if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+					return SPIFFEID{}, fmt.Errorf("SPIFFE ID %q has invalid path character %q", id, c)
+				}
+			}
+		}
+	}
+	return SPIFFEID{TrustDomain: td, Path: path}, nil
+}
+
+// NewSPIFFEVerifier returns a Verifier which parses and verifies the JWT-SVIDs of trustDomain issued to audience.
+// Tokens are verified with the jwt-svid keys of the SPIFFE trust bundle served by the https_web bundle endpoint bundleURL.
+// Their iss claim is ignored and their sub claim must be a SPIFFE ID of trustDomain, as returned by ParseSPIFFEID.
+// In a VerifierSet, the tokens whose sub claim starts with spiffe://<trustDomain>/ are routed to the Verifier, whatever their issuer.
+// SPIFFE allows algs which aren't supported, only RS256, ES256 and EdDSA JWT-SVIDs are verified.
+func NewSPIFFEVerifier(bundleURL, trustDomain, audience string, opts ...Option) (*Verifier, error) {
+	return NewSPIFFEVerifierContext(context.Background(), NewHTTPKeyFetcher(bundleURL), trustDomain, audience, opts...)
+}
+
+// NewSPIFFEVerifierContext is like NewSPIFFEVerifier but accepts any KeyFetcher of a SPIFFE trust bundle,
+// e.g. an HTTPKeyFetcher authenticating the https_spiffe bundle endpoint with WithHTTPClient.
+// ctx is passed to keyFetcher for the initial key retrieval only.
+func NewSPIFFEVerifierContext(ctx context.Context, keyFetcher KeyFetcher, trustDomain, audience string, opts ...Option) (*Verifier, error) {
+	if _, err := ParseSPIFFEID("spiffe://" + trustDomain); err != nil || strings.Contains(trustDomain, "/") {
+		return nil, fmt.Errorf("invalid SPIFFE trust domain %q", trustDomain)
+	}
+	opts = append([]Option{WithKeyFormat(FormatSPIFFEBundle), withSPIFFESubject(trustDomain)}, opts...)
+	return newFetchingVerifier(ctx, keyFetcher, "", audience, opts)
+}
+
+// withSPIFFESubject accepts any issuer of tokens whose subject is a SPIFFE ID of trustDomain,
+// so that a VerifierSet routes only those tokens to the Verifier, and checks the SPIFFE ID.
+func withSPIFFESubject(trustDomain string) Option {
+	prefix := "spiffe://" + trustDomain + "/"
+	return func(v *Verifier) {
+		v.checkIssuer = func(token *JWT) error {
+			if !strings.HasPrefix(token.Claims.SUB, prefix) {
+				return fmt.Errorf("%w, subject %v not of SPIFFE trust domain %v", ErrInvalidIssuer, token.Claims.SUB, trustDomain)
+			}
+			return nil
+		}
+		v.checks = append(v.checks, func(token *JWT) error {
+			id, err := ParseSPIFFEID(token.Claims.SUB)
+			if err != nil {
+				return err
+			}
+			if id.TrustDomain != trustDomain {
+				return fmt.Errorf("SPIFFE ID %v not of trust domain %v", id, trustDomain)
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseSPIFFEID(t *testing.T) {
+	tests := []struct {
+		id    string
+		want  SPIFFEID
+		valid bool
+	}{
+		{"spiffe://example.org/ns/prod/sa/api", SPIFFEID{"example.org", "/ns/prod/sa/api"}, true},
+		{"spiffe://example.org", SPIFFEID{"example.org", ""}, true},
+		{"https://example.org/api", SPIFFEID{}, false},
+		{"spiffe:///api", SPIFFEID{}, false},
+		{"spiffe://Example.org/api", SPIFFEID{}, false},
+		{"spiffe://example.org/", SPIFFEID{}, false},
+		{"spiffe://example.org/a/../b", SPIFFEID{}, false},
+		{"spiffe://example.org:8080/api", SPIFFEID{}, false},
+		{"spiffe://example.org/api?x=1", SPIFFEID{}, false},
+	}
+	for _, test := range tests {
+		id, err := ParseSPIFFEID(test.id)
+		if test.valid && (err != nil || id != test.want || id.String() != test.id) {
+			t.Errorf("parse %v returned %v, %v", test.id, id, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("invalid SPIFFE ID %v not throwing error", test.id)
+		}
+	}
+}
+
+func TestSPIFFEVerifier(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	x := base64.RawURLEncoding.EncodeToString(pub)
+	bundle := fmt.Sprintf(`{"spiffe_refresh_hint": 300, "keys": [
+		{"use":"x509-svid","kty":"OKP","crv":"Ed25519","x":"%v","x5c":["invalid"]},
+		{"use":"jwt-svid","kty":"OKP","crv":"Ed25519","kid":"test","x":"%v"}]}`, x, x)
+	ver, err := NewSPIFFEVerifierContext(context.Background(), keyGetterFunc(bundle), "example.org", "spiffe://example.org/db")
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"svid", map[string]interface{}{"iss": nil, "sub": "spiffe://example.org/api", "aud": []string{"spiffe://example.org/db"}}, true},
+		{"svid with issuer", map[string]interface{}{"iss": "https://spire.example.org", "sub": "spiffe://example.org/api", "aud": "spiffe://example.org/db"}, true},
+		{"other audience", map[string]interface{}{"sub": "spiffe://example.org/api", "aud": "spiffe://example.org/cache"}, false},
+		{"other trust domain", map[string]interface{}{"sub": "spiffe://example.com/api", "aud": "spiffe://example.org/db"}, false},
+		{"not a SPIFFE ID", map[string]interface{}{"sub": "1234", "aud": "spiffe://example.org/db"}, false},
+	}
+	for _, test := range tests {
+		_, err = ver.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+
+	// a VerifierSet routes only the SVIDs of the trust domain to the SPIFFE verifier
+	other, jwks := testEd25519Key(t)
+	google, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	set := NewVerifierSet(ver, google)
+	if _, err := set.ParseAndVerify(testToken(t, key, nil, tests[0].claims)); err != nil {
+		t.Errorf("svid of set fail, %v", err)
+	}
+	if _, err := set.ParseAndVerify(testToken(t, other, nil, nil)); err != nil {
+		t.Errorf("token of other issuer of set fail, %v", err)
+	}
+	spiffeOnly := NewVerifierSet(ver)
+	for _, claims := range []map[string]interface{}{
+		{"iss": "https://evil.example.com", "sub": "1234", "aud": "spiffe://example.org/db"},
+		{"sub": "spiffe://example.com/api", "aud": "spiffe://example.org/db"},
+	} {
+		_, err := spiffeOnly.ParseAndVerify(testToken(t, key, nil, claims))
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Kind != KindInvalidIssuer || !strings.Contains(err.Error(), "no verifier") {
+			t.Errorf("token of sub %v routed to the SPIFFE verifier, %v", claims["sub"], err)
+		}
+	}
+
+	if _, err := NewSPIFFEVerifierContext(context.Background(), keyGetterFunc(bundle), "example.org/api", "aud"); err == nil {
+		t.Errorf("invalid trust domain not throwing error")
+	}
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats is a snapshot of the key cache of a Verifier, e.g. for health endpoints and dashboards.
+type Stats struct {
+	// KIDs are the key IDs of the cached keys, sorted.
+	KIDs []string
+	// KeysExpire is the time the cached keys expire, zero for static keys.
+	KeysExpire time.Time
+	// LastRefresh is the time of the last key fetch, zero if the keys were never fetched.
+	LastRefresh time.Time
+	// LastRefreshError is the error of the last key fetch, nil if it succeeded.
+	LastRefreshError error
+	// FetchErrors is the number of failed key fetches.
+	FetchErrors int
+}
+
+// Stats returns a snapshot of the key cache of the Verifier.
+func (v *Verifier) Stats() Stats {
+	return v.keys.stats()
+}
+
+// stats returns a snapshot of the cache.
+func (v *keyCache) stats() Stats {
+	keys := v.snapshot()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := Stats{
+		KIDs:             make([]string, 0, len(keys.publicKeys)),
+		KeysExpire:       keys.expires,
+		LastRefresh:      v.lastFetch,
+		LastRefreshError: v.lastFetchErr,
+		FetchErrors:      v.fetchErrors,
+	}
+	for kid := range keys.publicKeys {
+		s.KIDs = append(s.KIDs, kid)
+	}
+	sort.Strings(s.KIDs)
+	return s
+}
+
+// recordFetch sets the outcome of a key fetch for stats.
+func (v *keyCache) recordFetch(err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lastFetch = time.Now()
+	v.lastFetchErr = err
+	if err != nil {
+		v.fetchErrors++
+	}
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"crypto"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifierStats(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	fail := false
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		if fail {
+			return nil, time.Time{}, errors.New("unavailable")
+		}
+		return io.NopCloser(strings.NewReader(jwks)), time.Now().Add(-time.Second), nil
+	}
+	ver, err := NewVerifier(fetcher, testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	s := ver.Stats()
+	if len(s.KIDs) != 1 || s.KIDs[0] != "test" || s.LastRefresh.IsZero() || s.LastRefreshError != nil || s.FetchErrors != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	fail = true
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err == nil {
+		t.Errorf("failed fetch not throwing error")
+	}
+	s = ver.Stats()
+	if len(s.KIDs) != 1 || s.LastRefreshError == nil || s.FetchErrors != 1 {
+		t.Errorf("unexpected stats after failed fetch %+v", s)
+	}
+
+	static, err := NewVerifierWithKeys(map[string]crypto.PublicKey{"a": key.Public(), "b": key.Public()}, testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	s = static.Stats()
+	if len(s.KIDs) != 2 || s.KIDs[0] != "a" || !s.KeysExpire.IsZero() || !s.LastRefresh.IsZero() {
+		t.Errorf("unexpected static stats %+v", s)
+	}
+}
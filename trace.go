@@ -0,0 +1,77 @@
+package jwt
+
+import "context"
+
+// Tracer starts the spans of verifications and key fetches, e.g. an adapter of an OpenTelemetry trace.Tracer,
+// which keeps the package free of the OpenTelemetry dependency:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, jwt.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value interface{}) {
+//		s.Span.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//
+//	func (s otelSpan) End(err error) {
+//		if err != nil {
+//			s.Span.RecordError(err)
+//			s.Span.SetStatus(codes.Error, jwt.ErrorCode(err))
+//		}
+//		s.Span.End()
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// End ends the span, err is the failure of the operation, if any.
+	End(err error)
+}
+
+// WithTracer traces verifications with t, with the jwt.iss, jwt.kid and jwt.alg attributes of the unverified token and
+// the jwt.cache_hit attribute reporting whether the keys were cached, and traces key fetches.
+func WithTracer(t Tracer) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.tracer = t
+	}
+}
+
+// WithHTTPTracer traces the requests of the HTTPKeyFetcher with t, with the url and the jwt.attempts attribute.
+func WithHTTPTracer(t Tracer) HTTPOption {
+	return func(f *HTTPKeyFetcher) {
+		f.tracer = t
+	}
+}
+
+// endVerifySpan ends the span of the verification of token, which is nil if it's malformed.
+func endVerifySpan(span Span, token *JWT, fetched bool, err error) {
+	if token != nil {
+		span.SetAttribute("jwt.iss", token.Claims.ISS)
+		span.SetAttribute("jwt.kid", token.Header.KID)
+		span.SetAttribute("jwt.alg", token.Header.ALG)
+	}
+	span.SetAttribute("jwt.cache_hit", !fetched)
+	span.End(err)
+}
+
+type fetchedKey struct{}
+
+// withFetchReport returns a copy of ctx in which key fetches are reported to fetched.
+func withFetchReport(ctx context.Context, fetched *bool) context.Context {
+	return context.WithValue(ctx, fetchedKey{}, fetched)
+}
+
+// reportFetch reports a key fetch to the verification of ctx, if any.
+func reportFetch(ctx context.Context) {
+	if fetched, ok := ctx.Value(fetchedKey{}).(*bool); ok {
+		*fetched = true
+	}
+}
@@ -0,0 +1,108 @@
+package jwt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordedSpan is a span recorded by recordingTracer.
+type recordedSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.err, s.ended = err, true
+}
+
+// recordingTracer records the started spans.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+func TestWithTracer(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	tracer := &recordingTracer{}
+	expired := true
+	var fetcher KeyFetcherFunc = func() (io.ReadCloser, time.Time, error) {
+		expires := time.Now().Add(time.Hour)
+		if expired {
+			expires = time.Now().Add(-time.Second)
+		}
+		return io.NopCloser(strings.NewReader(jwks)), expires, nil
+	}
+	ver, err := NewVerifier(fetcher, testClientID, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	expired = false
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, map[string]interface{}{"aud": "other"})); err == nil {
+		t.Errorf("invalid audience not throwing error")
+	}
+
+	var names []string
+	for _, s := range tracer.spans {
+		names = append(names, s.name)
+		if !s.ended {
+			t.Errorf("span %v not ended", s.name)
+		}
+	}
+	if strings.Join(names, ",") != "jwt.FetchKeys,jwt.ParseAndVerify,jwt.FetchKeys,jwt.ParseAndVerify" {
+		t.Fatalf("unexpected spans %v", names)
+	}
+	miss, hit := tracer.spans[1], tracer.spans[3]
+	if miss.attrs["jwt.cache_hit"] != false || miss.attrs["jwt.kid"] != "test" || miss.attrs["jwt.iss"] != "https://accounts.google.com" || miss.err != nil {
+		t.Errorf("unexpected span of cache miss %+v", miss)
+	}
+	if hit.attrs["jwt.cache_hit"] != true || hit.err == nil {
+		t.Errorf("unexpected span of failed verification %+v", hit)
+	}
+}
+
+func TestWithHTTPTracer(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts++; attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, validKey)
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	f := NewHTTPKeyFetcher(srv.URL, WithRetry(1, time.Millisecond, time.Millisecond), WithHTTPTracer(tracer))
+	r, _, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed, %v", err)
+	}
+	r.Close()
+	if len(tracer.spans) != 1 || tracer.spans[0].attrs["url"] != srv.URL || tracer.spans[0].attrs["jwt.attempts"] != 2 || !tracer.spans[0].ended {
+		t.Errorf("unexpected spans %+v", tracer.spans)
+	}
+}
@@ -0,0 +1,95 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// VerifierSet routes tokens to the Verifiers accepting their issuer, e.g. for an API accepting tokens of several issuers.
+// Verifiers may be added concurrently with verifying tokens.
+type VerifierSet struct {
+	mu sync.RWMutex
+	// byIssuer maps issuers to the verifiers expecting them, templated holds the verifiers with a custom issuer check,
+	// e.g. those of a multi-tenant NewAzureVerifier
+	byIssuer  map[string][]*Verifier
+	templated []*Verifier
+}
+
+// NewVerifierSet returns a VerifierSet of verifiers.
+func NewVerifierSet(verifiers ...*Verifier) *VerifierSet {
+	s := &VerifierSet{byIssuer: make(map[string][]*Verifier)}
+	for _, v := range verifiers {
+		s.Add(v)
+	}
+	return s
+}
+
+// Add adds v to the set. Several verifiers of an issuer, e.g. of different audiences, are tried in the order they were added.
+func (s *VerifierSet) Add(v *Verifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v.checkIssuer != nil {
+		s.templated = append(s.templated, v)
+		return
+	}
+	s.byIssuer[v.issuer] = append(s.byIssuer[v.issuer], v)
+}
+
+// ParseAndVerify returns a Go representation of tokenString verified by a Verifier accepting its unverified issuer.
+// A non-nil error implies that the token is invalid.
+func (s *VerifierSet) ParseAndVerify(tokenString string) (*JWT, error) {
+	return s.ParseAndVerifyContext(context.Background(), tokenString)
+}
+
+// ParseAndVerifyContext is like ParseAndVerify, ctx is passed to the KeyFetcher if the keys need to be refreshed.
+func (s *VerifierSet) ParseAndVerifyContext(ctx context.Context, tokenString string) (*JWT, error) {
+	unverified, _, err := splitJWT(tokenString, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	verifiers := append([]*Verifier(nil), s.byIssuer[unverified.Claims.ISS]...)
+	for _, v := range s.templated {
+		if v.verifyIssuer(unverified) == nil {
+			verifiers = append(verifiers, v)
+		}
+	}
+	s.mu.RUnlock()
+	if len(verifiers) == 0 {
+		return nil, &ValidationError{Kind: KindInvalidIssuer, Claim: "iss", Actual: unverified.Claims.ISS, KID: unverified.Header.KID,
+			Err: fmt.Errorf("%w, no verifier for issuer %v", ErrInvalidIssuer, unverified.Claims.ISS)}
+	}
+
+	var firstErr error
+	for _, v := range verifiers {
+		token, err := v.ParseAndVerifyContext(ctx, tokenString)
+		if err == nil {
+			return token, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// Close closes all verifiers of the set, returning the first error.
+func (s *VerifierSet) Close() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var firstErr error
+	closeAll := func(verifiers []*Verifier) {
+		for _, v := range verifiers {
+			if err := v.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for _, verifiers := range s.byIssuer {
+		closeAll(verifiers)
+	}
+	closeAll(s.templated)
+	return firstErr
+}
@@ -0,0 +1,52 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestVerifierSet(t *testing.T) {
+	key, jwks := testEd25519Key(t)
+	google, err := NewVerifier(keyGetterFunc(jwks), testClientID)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	googleOther, err := NewVerifier(keyGetterFunc(jwks), "other.apps.googleusercontent.com")
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	internal, err := NewVerifier(keyGetterFunc(jwks), "api", WithIssuer("https://auth.internal"))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	azure, err := newAzureVerifier(context.Background(), keyGetterFunc(jwks), "common", "api", nil)
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	set := NewVerifierSet(google, googleOther, internal)
+	set.Add(azure)
+	defer set.Close()
+
+	const tid = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		valid  bool
+	}{
+		{"google", nil, true},
+		{"google other audience", map[string]interface{}{"aud": "other.apps.googleusercontent.com"}, true},
+		{"google unknown audience", map[string]interface{}{"aud": "api"}, false},
+		{"internal", map[string]interface{}{"iss": "https://auth.internal", "aud": "api"}, true},
+		{"azure", map[string]interface{}{"iss": "https://login.microsoftonline.com/" + tid + "/v2.0", "tid": tid, "aud": "api"}, true},
+		{"unknown issuer", map[string]interface{}{"iss": "https://evil.example.com", "aud": "api"}, false},
+	}
+	for _, test := range tests {
+		_, err := set.ParseAndVerify(testToken(t, key, nil, test.claims))
+		if test.valid && err != nil {
+			t.Errorf("%v: token parse fail, %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%v: invalid token not throwing error", test.name)
+		}
+	}
+}
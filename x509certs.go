@@ -0,0 +1,106 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+)
+
+// googleV1CertsURL publishes the certificates of Google's OAuth2 signing keys, the same keys as googleCertsURL.
+const googleV1CertsURL = "https://www.googleapis.com/oauth2/v1/certs"
+
+// firebaseCertsURL publishes the certificates of the keys which sign Firebase Authentication ID tokens.
+const firebaseCertsURL = "https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com"
+
+// KeyFormat is the format of the keys returned by a KeyFetcher.
+type KeyFormat int
+
+const (
+	// FormatJWKS is a JSON Web Key Set, the default.
+	FormatJWKS KeyFormat = iota
+	// FormatX509 is a JSON object mapping key IDs to PEM encoded X.509 certificates, as published for Firebase Authentication.
+	FormatX509
+	// FormatAuto is FormatJWKS if the key set has a "keys" member and FormatX509 otherwise,
+	// for fetchers which may return either format, e.g. a CompositeKeyFetcher falling back to NewGoogleV1KeyFetcher.
+	FormatAuto
+	// FormatSPIFFEBundle is a SPIFFE trust bundle, a JSON Web Key Set of which only the keys with use jwt-svid are used.
+	FormatSPIFFEBundle
+)
+
+// WithKeyFormat sets the format of the keys returned by the KeyFetcher.
+func WithKeyFormat(format KeyFormat) Option {
+	return func(v *Verifier) {
+		v.cacheConfig.jwks.format = format
+	}
+}
+
+// NewFirebaseKeyFetcher returns an HTTPKeyFetcher which obtains the certificates of the keys which sign
+// Firebase Authentication ID tokens, in FormatX509.
+func NewFirebaseKeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(firebaseCertsURL, opts...)
+}
+
+// NewGoogleV1KeyFetcher returns an HTTPKeyFetcher which obtains the certificates of Google's OAuth2 signing keys
+// in FormatX509, an alternative to NewGoogleKeyFetcher where the JWKS endpoint is blocked.
+func NewGoogleV1KeyFetcher(opts ...HTTPOption) *HTTPKeyFetcher {
+	return NewHTTPKeyFetcher(googleV1CertsURL, opts...)
+}
+
+// ParseX509Certs returns the public keys of a JSON object mapping key IDs to PEM encoded X.509 certificates.
+// The key set is limited as by ParseJWKS.
+func ParseX509Certs(r io.Reader) (map[string]crypto.PublicKey, error) {
+	c := defaultJWKSConfig
+	c.format = FormatX509
+	keys, err := c.parse(r)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]crypto.PublicKey, len(keys))
+	for kid, k := range keys {
+		m[kid] = k.key
+	}
+	return m, nil
+}
+
+// isJWKS reports whether the JSON object b has a "keys" member.
+func isJWKS(b []byte) bool {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return true
+	}
+	_, ok := members["keys"]
+	return ok
+}
+
+func (c jwksConfig) parseX509(b []byte) (map[string]verificationKey, error) {
+	var certs map[string]string
+	if err := json.Unmarshal(b, &certs); err != nil {
+		return nil, fmt.Errorf("decode json %s - %w", b, err)
+	}
+	if c.maxKeys > 0 && len(certs) > c.maxKeys {
+		return nil, &LimitError{Limit: "keys", Max: int64(c.maxKeys)}
+	}
+
+	m := make(map[string]verificationKey, len(certs))
+	for kid, enc := range certs {
+		block, _ := pem.Decode([]byte(enc))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("no PEM certificate for key %v", kid)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse certificate of key %v, %w", kid, err)
+		}
+		if err := checkKeys(map[string]crypto.PublicKey{kid: cert.PublicKey}); err != nil {
+			continue
+		}
+		m[kid] = verificationKey{key: cert.PublicKey}
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no public keys %s", b)
+	}
+	return m, nil
+}
@@ -0,0 +1,75 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatX509(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	cert := testCert(t, key, nil, nil)
+	certs, err := json.Marshal(map[string]string{
+		"test": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+	})
+	if err != nil {
+		t.Fatalf("marshal certificates failed, %v", err)
+	}
+
+	keys, err := ParseX509Certs(strings.NewReader(string(certs)))
+	if err != nil {
+		t.Fatalf("parse certificates failed, %v", err)
+	}
+	if k, ok := keys["test"].(*rsa.PublicKey); !ok || !k.Equal(&key.PublicKey) {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	var fetcher KeyFetcherFunc = func() (r io.ReadCloser, expires time.Time, err error) {
+		return io.NopCloser(strings.NewReader(string(certs))), time.Now().Add(time.Hour), nil
+	}
+	ver, err := NewVerifierContext(context.Background(), fetcher, testClientID, WithKeyFormat(FormatX509))
+	if err != nil {
+		t.Fatalf("New Verifier failed, %v", err)
+	}
+	if _, err := ver.ParseAndVerify(testToken(t, key, nil, nil)); err != nil {
+		t.Errorf("token parse fail, %v", err)
+	}
+
+	if _, err := ParseX509Certs(strings.NewReader(`{"test": "not pem"}`)); err == nil {
+		t.Errorf("invalid certificate not throwing error")
+	}
+}
+
+func TestFormatAuto(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key failed, %v", err)
+	}
+	cert := testCert(t, key, nil, nil)
+	certs, err := json.Marshal(map[string]string{
+		"test": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+	})
+	if err != nil {
+		t.Fatalf("marshal certificates failed, %v", err)
+	}
+
+	c := defaultJWKSConfig
+	c.format = FormatAuto
+	for _, keySet := range []string{string(certs), validKey} {
+		keys, err := c.parse(strings.NewReader(keySet))
+		if err != nil {
+			t.Errorf("parse %v failed, %v", keySet, err)
+		} else if len(keys) == 0 {
+			t.Errorf("no keys parsed from %v", keySet)
+		}
+	}
+}